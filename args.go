@@ -0,0 +1,238 @@
+package pyfmt
+
+import (
+	"net/textproto"
+	"reflect"
+	"strings"
+)
+
+// Args is a source of arguments for FmtArgs and MustArgs, for formatting directly from an existing
+// data source instead of building a map to pass to Fmt. Positional arguments are looked up by "{}"
+// and "{0}", and named arguments by "{name}". The rest of a compound field name, like
+// "{name.field}", is looked up in the argument as usual.
+type Args interface {
+	// Len returns the number of positional arguments.
+	Len() int
+	// Positional returns the positional argument at index i, which is less than Len().
+	Positional(i int) (interface{}, error)
+	// Named returns the argument with the name, or an error if there isn't one, which may be
+	// replaced by a default or a MissingKeyPolicy.
+	Named(name string) (interface{}, error)
+}
+
+// formatterArgs are Args that look up named arguments using the options of the Formatter that's
+// formatting them, e.g. to look up struct fields by their json tags.
+type formatterArgs interface {
+	namedWith(fm *Formatter, name string) (interface{}, error)
+}
+
+// FmtArgs is like Fmt, but gets its arguments from an Args.
+func FmtArgs(format string, args Args) (string, error) {
+	return std.FmtArgs(format, args)
+}
+
+// MustArgs is like FmtArgs, but panics on error.
+func MustArgs(format string, args Args) string {
+	return std.MustArgs(format, args)
+}
+
+// FmtArgs is like the package-level FmtArgs, but uses the Formatter's options.
+func (fm *Formatter) FmtArgs(format string, args Args) (string, error) {
+	f := newFormater(fm)
+	defer f.free()
+	f.src = args
+	err := f.doFormat(format)
+	if err != nil {
+		return "", err
+	}
+	s := string(f.buf.contents)
+	return s, nil
+}
+
+// MustArgs is like Formatter.FmtArgs, but panics on error.
+func (fm *Formatter) MustArgs(format string, args Args) string {
+	s, err := fm.FmtArgs(format, args)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// valueArgs are the arguments passed to Fmt.
+type valueArgs struct {
+	elems []interface{}
+}
+
+// Values returns the values as Args, which act just like the arguments to Fmt: the values are the
+// positional arguments, and names are looked up in the first value, which is usually a struct or
+// a map.
+func Values(a ...interface{}) Args {
+	return &valueArgs{a}
+}
+
+func (a *valueArgs) Len() int {
+	return len(a.elems)
+}
+
+func (a *valueArgs) Positional(i int) (interface{}, error) {
+	return a.elems[i], nil
+}
+
+func (a *valueArgs) Named(name string) (interface{}, error) {
+	return a.namedWith(std, name)
+}
+
+func (a *valueArgs) namedWith(fm *Formatter, name string) (interface{}, error) {
+	return fm.namedValue(a.elems, name)
+}
+
+// SliceArgs returns the elements of a slice or an array as positional Args. Panics if slice isn't
+// a slice or an array.
+func SliceArgs(slice interface{}) Args {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(Must("pyfmt: SliceArgs requires a slice or an array, got {:t}", slice))
+	}
+	return sliceArgs{v}
+}
+
+type sliceArgs struct {
+	v reflect.Value
+}
+
+func (a sliceArgs) Len() int {
+	return a.v.Len()
+}
+
+func (a sliceArgs) Positional(i int) (interface{}, error) {
+	return a.v.Index(i).Interface(), nil
+}
+
+func (a sliceArgs) Named(name string) (interface{}, error) {
+	return nil, Error("slice arguments have no names: {}", name)
+}
+
+// MapArgs returns the values of a map as named Args, like passing the map to Fmt. Panics if m isn't
+// a map.
+func MapArgs(m interface{}) Args {
+	if reflect.ValueOf(m).Kind() != reflect.Map {
+		panic(Must("pyfmt: MapArgs requires a map, got {:t}", m))
+	}
+	return Values(m)
+}
+
+// StructArgs returns the fields of a struct, or of the struct a pointer points to, as named Args,
+// like passing the struct to Fmt. Panics if s isn't a struct or a pointer to one.
+func StructArgs(s interface{}) Args {
+	v := reflect.ValueOf(s)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(Must("pyfmt: StructArgs requires a struct, got {:t}", s))
+	}
+	return Values(s)
+}
+
+// EnvArgs returns environment variables as named Args, from a list of "key=value" strings like
+// those returned by os.Environ.
+func EnvArgs(environ []string) Args {
+	env := make(stringArgs, len(environ))
+	for _, kv := range environ {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	return env
+}
+
+// stringArgs are named Args with string values.
+type stringArgs map[string]string
+
+func (a stringArgs) Len() int {
+	return 0
+}
+
+func (a stringArgs) Positional(i int) (interface{}, error) {
+	return nil, Error("index out of bounds: {}", i)
+}
+
+func (a stringArgs) Named(name string) (interface{}, error) {
+	if val, ok := a[name]; ok {
+		return val, nil
+	}
+	return nil, Error("could not find key: {}", name)
+}
+
+// QueryArgs returns the first value for each key of a url.Values, or any map from strings to
+// lists of strings, as named Args.
+func QueryArgs(values map[string][]string) Args {
+	return multiArgs{values, false}
+}
+
+// HeaderArgs returns the first value for each key of an http.Header as named Args. Names are
+// canonicalized like http.Header.Get, so "{content-type}" finds the Content-Type header.
+func HeaderArgs(header map[string][]string) Args {
+	return multiArgs{header, true}
+}
+
+// multiArgs are named Args with lists of string values, of which the first is used.
+type multiArgs struct {
+	values    map[string][]string
+	canonical bool
+}
+
+func (a multiArgs) Len() int {
+	return 0
+}
+
+func (a multiArgs) Positional(i int) (interface{}, error) {
+	return nil, Error("index out of bounds: {}", i)
+}
+
+func (a multiArgs) Named(name string) (interface{}, error) {
+	if a.canonical {
+		name = textproto.CanonicalMIMEHeaderKey(name)
+	}
+	if vals := a.values[name]; len(vals) > 0 {
+		return vals[0], nil
+	}
+	return nil, Error("could not find key: {}", name)
+}
+
+// RowArgs returns a database row as Args, with the values as positional arguments, which are also
+// named by their columns. The values may be pointers to the values, like those passed to
+// sql.Rows.Scan, and []byte values, which database drivers often return for text, are formatted as
+// strings.
+func RowArgs(columns []string, values []interface{}) Args {
+	return rowArgs{columns, values}
+}
+
+type rowArgs struct {
+	columns []string
+	values  []interface{}
+}
+
+func (a rowArgs) Len() int {
+	return len(a.values)
+}
+
+func (a rowArgs) Positional(i int) (interface{}, error) {
+	val := a.values[i]
+	if v := reflect.ValueOf(val); v.Kind() == reflect.Ptr && !v.IsNil() {
+		val = v.Elem().Interface()
+	}
+	if b, ok := val.([]byte); ok {
+		return string(b), nil
+	}
+	return val, nil
+}
+
+func (a rowArgs) Named(name string) (interface{}, error) {
+	for i, column := range a.columns {
+		if column == name && i < len(a.values) {
+			return a.Positional(i)
+		}
+	}
+	return nil, Error("could not find column: {}", name)
+}
@@ -0,0 +1,100 @@
+package pyfmt
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestFmtArgs(t *testing.T) {
+	type server struct {
+		Host string
+		Port int `json:"port"`
+	}
+	var id interface{} = int64(7)
+	var name interface{} = []byte("ann")
+
+	tests := []struct {
+		fmtStr string
+		args   Args
+		want   string
+	}{
+		{"{} {}", Values("a", 1), "a 1"},
+		{"{Host}:{Port}", Values(server{"localhost", 80}), "localhost:80"},
+		{"{1}-{0}", SliceArgs([]string{"a", "b"}), "b-a"},
+		{"{}{}{}", SliceArgs([3]int{1, 2, 3}), "123"},
+		{"{0[1]:>3}", SliceArgs([][]int{{4, 5}}), "  5"},
+		{"{a}{b}", MapArgs(map[string]int{"a": 1, "b": 2}), "12"},
+		{"{Host}:{Port:05d}", StructArgs(&server{"db", 5432}), "db:05432"},
+		{"{HOME} {SHELL:.4}", EnvArgs([]string{"HOME=/root", "SHELL=/bin/sh", "EMPTY=", "A=b=c"}), "/root /bin"},
+		{"[{EMPTY}] {A}", EnvArgs([]string{"EMPTY=", "A=b=c", "bad"}), "[] b=c"},
+		{"{q} {tag}", QueryArgs(url.Values{"q": {"go"}, "tag": {"x", "y"}}), "go x"},
+		{"{content-type}", HeaderArgs(http.Header{"Content-Type": {"text/plain"}}), "text/plain"},
+		{"{id}: {name:>5} {0}", RowArgs([]string{"id", "name"}, []interface{}{&id, &name}), "7:   ann 7"},
+		{"{name[0]}", RowArgs([]string{"name"}, []interface{}{"ann"}), "a"},
+	}
+
+	for _, test := range tests {
+		got, err := FmtArgs(test.fmtStr, test.args)
+		if err != nil {
+			t.Error(Must("FmtArgs({fmtStr}, {args}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("FmtArgs({fmtStr}, {args}) = {1}, Want: {want}", test, got))
+		}
+	}
+
+	json := &Formatter{JSONTags: true}
+	if got := json.MustArgs("{port}", StructArgs(server{Port: 80})); got != "80" {
+		t.Error(Must("MustArgs({{port}}) = {}, Want: 80", got))
+	}
+}
+
+func TestFmtArgsError(t *testing.T) {
+	tests := []struct {
+		fmtStr string
+		args   Args
+	}{
+		{"{2}", Values("a", 1)},
+		{"{}{}", Values("a")},
+		{"{x}", Values()},
+		{"{x}", SliceArgs([]int{1})},
+		{"{c}", MapArgs(map[string]int{"a": 1})},
+		{"{Missing}", StructArgs(struct{ A int }{})},
+		{"{0}", EnvArgs(nil)},
+		{"{PATH}", EnvArgs(nil)},
+		{"{q}", QueryArgs(url.Values{"q": {}})},
+		{"{Accept}", HeaderArgs(http.Header{})},
+		{"{email}", RowArgs([]string{"id"}, []interface{}{1})},
+		{"{1}", RowArgs([]string{"id"}, []interface{}{1})},
+	}
+
+	for _, test := range tests {
+		_, err := FmtArgs(test.fmtStr, test.args)
+		if err == nil {
+			t.Error(Must("FmtArgs({fmtStr}, {args}) did not error when expected!", test))
+		}
+	}
+}
+
+func TestArgsAdaptersPanic(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter func()
+	}{
+		{"SliceArgs", func() { SliceArgs(map[string]int{}) }},
+		{"MapArgs", func() { MapArgs([]int{}) }},
+		{"StructArgs", func() { StructArgs(5) }},
+	}
+
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error(Must("{name} did not panic when expected!", test))
+				}
+			}()
+			test.adapter()
+		}()
+	}
+}
@@ -0,0 +1,121 @@
+package pyfmt
+
+import (
+	"math"
+	"reflect"
+)
+
+// tagName is the struct tag key pyfmt reads field options from.
+const tagName = "pyfmt"
+
+// fieldTag holds the options parsed out of a `pyfmt:"name,omitempty"` struct tag.
+type fieldTag struct {
+	name      string
+	omitEmpty bool
+	skip      bool
+}
+
+// parseFieldTag parses the pyfmt tag of a struct field. A tag of "-" skips the field entirely, and
+// an empty name keeps the Go field name.
+func parseFieldTag(f reflect.StructField) fieldTag {
+	tag, ok := f.Tag.Lookup(tagName)
+	if !ok {
+		return fieldTag{name: f.Name}
+	}
+	if tag == "-" {
+		return fieldTag{skip: true}
+	}
+	name, opts := split(tag, ',')
+	t := fieldTag{name: name}
+	if t.name == "" {
+		t.name = f.Name
+	}
+	for opts != "" {
+		var opt string
+		opt, opts = split(opts, ',')
+		if opt == "omitempty" {
+			t.omitEmpty = true
+		}
+	}
+	return t
+}
+
+// writeDataclass writes a struct the way Python prints a dataclass: the type name followed by the
+// fields as keyword arguments, e.g. Point(x=1, y=2). Field values are written with Python repr()
+// rules, and nested structs are written as dataclasses too.
+func (p *pyRepr) writeDataclass(v reflect.Value) {
+	b := p.buf
+	name := v.Type().Name()
+	if name == "" {
+		name = "struct"
+	}
+	b.WriteString(name)
+	b.WriteString("(")
+	written := 0
+	for i := 0; i < v.NumField(); i++ {
+		tag := parseFieldTag(v.Type().Field(i))
+		field := v.Field(i)
+		if tag.skip || ((tag.omitEmpty || p.omitZero) && isZero(field)) {
+			continue
+		}
+		if written > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(tag.name)
+		b.WriteString("=")
+		p.write(field)
+		written++
+	}
+	b.WriteString(")")
+}
+
+// isZero returns true if v is the zero value of its type, like reflect.Value.IsZero, which isn't
+// available before Go 1.13.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return math.Float64bits(v.Float()) == 0
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return math.Float64bits(real(c)) == 0 && math.Float64bits(imag(c)) == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice,
+		reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return !v.IsValid()
+}
+
+// renderRepr replaces the value being rendered with its Python repr(), with structs written as
+// dataclasses, so that it can be aligned and truncated like a string. Explicitly asking for a repr
+// writes the fields of structs, at any depth, even if they have their own String method.
+func (r *render) renderRepr() {
+	var b buffer
+	p := newPyRepr(&b)
+	p.dataclass = true
+	p.omitZero = r.cfg.OmitZeroFields
+	p.write(valueOf(r.val))
+	r.val = string(b.contents)
+	r.renderVerb = "v"
+}
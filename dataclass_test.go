@@ -0,0 +1,137 @@
+package pyfmt
+
+import (
+	"reflect"
+	"testing"
+)
+
+type point struct {
+	X int `pyfmt:"x"`
+	Y int `pyfmt:"y"`
+}
+
+type segment struct {
+	Start point
+	End   *point
+	Label string `pyfmt:"label,omitempty"`
+	debug string `pyfmt:"-"`
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+// pin has its own String method, which the 'R' type ignores.
+type pin struct {
+	At point
+}
+
+func (pin) String() string { return "pin" }
+
+type tagged struct {
+	ID   int `pyfmt:",omitempty"`
+	Name string
+}
+
+func TestReprFormat(t *testing.T) {
+	loop := &node{Value: 1}
+	loop.Next = &node{Value: 2, Next: loop}
+
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{:R}", point{1, 2}, "point(x=1, y=2)"},
+		{"{:R}", &point{1, 2}, "point(x=1, y=2)"},
+		{"{:R}", segment{Start: point{1, 2}}, "segment(Start=point(x=1, y=2), End=None)"},
+		{"{:R}", segment{End: &point{3, 4}, Label: "a'b", debug: "x"}, `segment(Start=point(x=0, y=0), End=point(x=3, y=4), label="a'b")`},
+		{"{:R}", loop, "node(Value=1, Next=node(Value=2, Next=...))"},
+		{"{:R}", tagged{Name: "n"}, "tagged(Name='n')"},
+		{"{:R}", struct{ a []string }{[]string{"x"}}, "struct(a=['x'])"},
+		{"{:R}", []point{{1, 2}}, "[point(x=1, y=2)]"},
+		{"{:R}", map[string]point{"p": {}}, "{'p': point(x=0, y=0)}"},
+		{"{:R}", "text", "'text'"},
+		{"{:R}", nil, "None"},
+		{"{:>20R}", point{1, 2}, "     point(x=1, y=2)"},
+		{"{:.5R}", point{1, 2}, "point"},
+		{"{p:R}", map[string]interface{}{"p": point{1, 2}}, "point(x=1, y=2)"},
+		{"{:R}", pin{point{1, 2}}, "pin(At=point(x=1, y=2))"},
+		{"{:R}", []pin{{}}, "[pin(At=point(x=0, y=0))]"},
+		{"{:R}", []interface{}{&pin{}, nil}, "[pin(At=point(x=0, y=0)), None]"},
+		{"{:R}", map[string]*pin{"a": nil}, "{'a': None}"},
+		{"{}", []pin{{}}, "[pin]"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestReprOmitZeroFields(t *testing.T) {
+	f := &Formatter{OmitZeroFields: true}
+	got := f.Must("{:R}", segment{Start: point{X: 1}})
+	if want := "segment(Start=point(x=1))"; got != want {
+		t.Error(Must("Must({{:R}}) = {}, Want: {}", got, want))
+	}
+}
+
+func TestParseFieldTag(t *testing.T) {
+	typ := reflect.TypeOf(struct {
+		A int
+		B int `pyfmt:"b"`
+		C int `pyfmt:"c,omitempty"`
+		D int `pyfmt:",omitempty"`
+		E int `pyfmt:"-"`
+	}{})
+	want := []fieldTag{
+		{name: "A"},
+		{name: "b"},
+		{name: "c", omitEmpty: true},
+		{name: "D", omitEmpty: true},
+		{skip: true},
+	}
+	for i, w := range want {
+		if got := parseFieldTag(typ.Field(i)); got != w {
+			t.Error(Must("parseFieldTag({}) = {:s}, Want: {:s}", typ.Field(i).Name, got, w))
+		}
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	var nilPtr *point
+	tests := []struct {
+		param interface{}
+		want  bool
+	}{
+		{0, true},
+		{1, false},
+		{0.0, true},
+		{complex(0, 1), false},
+		{"", true},
+		{"a", false},
+		{false, true},
+		{nilPtr, true},
+		{&point{}, false},
+		{[]int{}, false},
+		{[]int(nil), true},
+		{map[string]int(nil), true},
+		{[2]int{}, true},
+		{[2]int{0, 1}, false},
+		{point{}, true},
+		{point{Y: 1}, false},
+		{segment{Start: point{X: 1}}, false},
+	}
+	for _, test := range tests {
+		if got := isZero(reflect.ValueOf(test.param)); got != test.want {
+			t.Error(Must("isZero({param:r}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
@@ -17,7 +17,7 @@ literal '{' and '}' runes to be emitted in the output.
 Each format item consists of a 'field name', which indicates which value from the argument list to
 use, and a 'format specifier', which indicates how to format that item.
 
-Requires Golang 1.5 or newer.
+Requires Golang 1.9 or newer.
 
 Functions
 
@@ -37,7 +37,7 @@ Simple field names:
 
 The simplest look up treats the argument list as just a list. There are two possible ways to look up
 elements from this list. First, by {}, which gets the 'next' item and by {n}, which gets the nth
-item. Accessing these two ways is independent, and
+item. Accessing these two ways is independent but cannot be mixed, and
 
   pyfmt.Must("{} {} {}", ...)
 
@@ -77,6 +77,110 @@ and struct fields are accessed with a period ('.')
 
   pyfmt.Must("{foo.bar.baz}", MyStruct{foo: Foo{bar: Bar{baz: "test"}}}) --> "test"
 
+Like Python, negative indexes count back from the end of a list, or a string, whose characters can
+also be indexed. A range of indexes gets a sub-list or a substring, which is then formatted like
+any other value. Either end of a range may be left out or negative, and ends past the end of the
+list are clamped to it:
+
+  pyfmt.Must("{0[-1]}", []string{"a", "b", "c"}) --> "c"
+  pyfmt.Must("{0[1:]}", []string{"a", "b", "c"}) --> "[b c]"
+  pyfmt.Must("{0[:3]:>5}", "abcdef") --> "  abc"
+
+A key in square brackets is everything up to the closing bracket, so it may contain '.' or ':'.
+Keys containing brackets or the closing delimiter, or that need to be unambiguous, can be quoted
+with single or double quotes, in which a backslash escapes the next character:
+
+  pyfmt.Must(`{labels["app.kubernetes.io/name"]}`, pod) --> "web"
+  pyfmt.Must(`{0['a[0]']}`, map[string]int{"a[0]": 1}) --> "1"
+  pyfmt.Must(`{0['a}b']}`, map[string]int{"a}b": 2}) --> "2"
+
+Map keys don't need to be strings. The key in the field name is converted to the map's key type,
+which may be any integer, float, bool, or string type, or a type implementing
+encoding.TextUnmarshaler. For maps with interface{} keys, like those YAML decoders produce, the key
+is tried as a string first, and then as an int, a float64, or a bool:
+
+  pyfmt.Must("{ports[8080]}", map[string]map[int]string{"ports": {8080: "http"}}) --> "http"
+
+Struct fields
+
+Struct fields are looked up by the name in their `pyfmt:"name"` tag, if they have one, or else by
+their Go name. Fields tagged `pyfmt:"-"` can't be looked up. Setting JSONTags on a Formatter also
+looks up fields by the name in their json tag, and setting IgnoreFieldCase matches field names
+ignoring case, if no field matches exactly:
+
+  type Account struct {
+    UserID int    `pyfmt:"user_id"`
+    Email  string `json:"email"`
+  }
+  pyfmt.Must("{user_id}", acct) --> "7"
+  (&pyfmt.Formatter{JSONTags: true}).Must("{email}", acct) --> "a@b.c"
+
+Fields of embedded structs are promoted as in Go, so "{ID}" finds the ID field of an embedded
+struct, unless the outer struct has its own ID field. If structs embedded at the same depth both
+have the field, the name is ambiguous, and is an error.
+
+Methods
+
+If a field name doesn't match a field, key, or index, but does match an exported method that takes
+no arguments and returns a value, or a value and an error, the method is called, and its result is
+used. An error returned by the method is returned as a format error:
+
+  pyfmt.Must("{user.FullName}", map[string]User{"user": u}) --> "Ann Lee"
+
+Methods with pointer receivers can be called on values too. When formatting untrusted format
+strings, set DisableMethods on a Formatter to stop field names from calling methods.
+
+Custom field lookup
+
+Types that can't be walked by reflection, like dynamic config objects or lazily-loaded records, can
+look up the parts of field names themselves, like Python's __getitem__ and __getattr__. A type
+implementing ItemGetter has its PyGetItem method called for "[key]" parts of a field name, and a
+type implementing AttrGetter has its PyGetAttr method called for ".name" parts. A type
+implementing only one of them has it called for both. The first part of a field name counts as a
+key. An error returned by the methods means the name can't be found, so it's returned as a format
+error, unless the field has a default or the Formatter has a MissingKey policy.
+
+  func (c Config) PyGetItem(key string) (interface{}, error) { return c.Lookup(key) }
+  pyfmt.Must("{db.host}", cfg) --> "localhost"
+
+Argument sources
+
+To format directly from an existing data source, without building a map to pass to Fmt, 'FmtArgs'
+and 'MustArgs' take an Args, which looks up positional arguments by index and named arguments by
+name. pyfmt has Args adapters for common data sources:
+
+  pyfmt.MustArgs("{HOME}", pyfmt.EnvArgs(os.Environ())) --> "/root"
+  pyfmt.MustArgs("{q} page {page}", pyfmt.QueryArgs(req.URL.Query())) --> "go page 2"
+  pyfmt.MustArgs("{content-type}", pyfmt.HeaderArgs(req.Header)) --> "text/plain"
+  pyfmt.MustArgs("{id}: {name}", pyfmt.RowArgs(columns, values)) --> "7: ann"
+
+Values, SliceArgs, MapArgs, and StructArgs adapt values like the arguments to Fmt. Any other type
+implementing the Args interface can be used too.
+
+A Scope is an Args that searches a stack of maps and structs in order for named arguments, like
+Python's ChainMap, so that values can be layered over defaults. Pushing a child scope is cheap, and
+leaves its parent unchanged. Only names that can't be found fall through to the parent scopes, and
+other errors, like one returned by a method, are returned. If no map or struct has the name, the
+error lists the scopes searched:
+
+  defaults := pyfmt.NewScope(tenant, globals)
+  scope := defaults.PushNamed("user", map[string]interface{}{"user": "ann", "quota": 50})
+  pyfmt.MustArgs("{user} has {quota}GB of {product}", scope) --> "ann has 50GB of Acme"
+
+Lazy arguments
+
+Arguments that are expensive to compute, like stack traces or serialized requests, can be wrapped
+with 'Lazy', or with 'LazyErr' if computing them can fail. They're only computed if the format
+string uses them, and at most once per format, however many times they're used. They may be passed
+as arguments, or held in the maps, structs, and slices passed as arguments:
+
+  vars := map[string]interface{}{"user": u, "trace": pyfmt.Lazy(func() interface{} {
+    return string(debug.Stack())
+  })}
+  pyfmt.Must("{user} logged in", vars) --> "ann logged in", without computing the stack trace
+
+An error returned by a LazyErr function is returned as a format error.
+
 Formatting
 
 If after a simple or complex field name, there's a ':', what follows is considered to be the format
@@ -84,7 +188,7 @@ specifier. If a type satisfies the PyFormat interface (discussed below), the for
 be passed to that, but otherwise, it will fall back to the default formatter, which expects the
 standard format specifier:
 
-  [[fill]align][sign][#][0][minimumwidth][.precision][type]
+  [[fill]align][sign][#][0][minimumwidth][grouping][.precision][type][~[bits]]
 
 The optional align feature can be one of the following:
 
@@ -108,6 +212,11 @@ prepended, respectively.
 The minimumwidth field specifies a minimum width, which is helpful when used with alignment. If
 preceded with a zero, numbers will be zero-padded.
 
+The optional grouping can be ',' or '_', which separates the thousands of a number with that
+character, e.g. "1,234,567". With the binary, octal, and hex types, '_' separates every four digits
+instead, e.g. "dead_beef". Grouping can only be used with the numeric types, and zero-padding is
+grouped as well, so "{:010,}" formats 1234 as "00,001,234".
+
 The precision field specifies a maximum width for non-floating point, non-integer types, and the
 number of points to show after the decimal point for floating types.
 
@@ -121,6 +230,16 @@ For integers:
   'x' - Hexadecimal, base 16
   'X' - Hexadecimal, base 16, using upper-case letters
 
+Like Python, negative integers are printed with a minus sign by the 'b', 'o', 'x', and 'X' types, so
+"{:x}" on int8(-1) gives "-1". Adding a '~' after the type prints negative integers as their two's
+complement instead, using the size of their Go type, or the number of bits given after the '~':
+
+  pyfmt.Must("{:x~}", int8(-1)) --> "ff"
+  pyfmt.Must("{:#_x~}", int32(-2)) --> "0xffff_fffe"
+  pyfmt.Must("{:X~12}", -1) --> "FFF"
+
+It's an error if the number doesn't fit in the number of bits.
+
 For floats and complex numbers:
 
   'e' - Scientific notation
@@ -133,6 +252,38 @@ For floats and complex numbers:
   '%' - Percentage, multiplies the number by 100 and displays it with a '%' sign. Can also be
         applied to integer types.
 
+For sizes and other quantities, there are also types that scale the number to a unit or prefix:
+
+  'iec' - Binary byte size, in B, KiB, MiB, GiB, and so on (powers of 1024)
+  'si'  - Decimal byte size, in B, kB, MB, GB, and so on (powers of 1000)
+  'S'   - SI prefix, e.g. k, M, G, or m, µ, n for quantities less than one, followed by the unit
+          given after the format item
+  'eng' - Engineering notation, like 'e', but with an exponent that's a multiple of three
+
+For example:
+
+  pyfmt.Must("{:.1iec}", 1610612736) --> "1.5 GiB"
+  pyfmt.Must("{:.3S}Hz", 2.4e9) --> "2.400 GHz"
+  pyfmt.Must("{:.2eng}", 12300) --> "12.30e+03"
+
+These work with all integer and float types, as well as math/big numbers. The precision sets the
+number of digits after the decimal point. Without one, 'eng' shows six like 'e', and the others show
+at most two, dropping trailing zeros. The sign, fill, alignment, and width work like they do for
+other numbers.
+
+Integers can also be written out for people to read:
+
+  'ordinal' - The number with its ordinal suffix, e.g. 1st, 22nd, 113th
+  'words'   - The number spelled out as words, e.g. forty-two
+
+By default these use English. Other languages can be supported by setting the Formatter's Speller
+option to a type implementing the NumberSpeller interface:
+
+  type NumberSpeller interface {
+    Ordinal(n int64) string
+    Cardinal(n int64) string
+  }
+
 Special Formatting Types
 
 For some types (most notably structs), the default formatter doesn't quite give enough information
@@ -141,12 +292,141 @@ Additionally, sometimes it's useful to print the type of a variable while format
 pyfmt allows for some special formatting types that aren't in the Python format syntax.
 
   'r' - convert the value to its Go-syntax representation
+  'R' - convert the value to its Python repr(), printing structs like Python dataclasses
+  'p' - pretty-print the value over multiple lines, as an indented tree
   't' - convert the value to its Go type
   's' - if printing a struct, print the struct field names
 
 These are equivalent to the `%#v`, `%T` and `%+v` format strings in the "fmt" package, but don't
 have an exact equivalent in Python.
 
+The 'R' type prints values the way Python's repr() would, with strings quoted, slices and maps as
+Python lists and dicts, and structs in the style of a Python dataclass:
+
+  pyfmt.Must("{:R}", Point{X: 1, Y: 2}) --> "Point(X=1, Y=2)"
+
+Nested structs and pointers are followed, and a pointer cycle is printed as '...'. Structs are
+printed field by field at any depth, even if they have their own String method. Field names can
+be changed with a `pyfmt` struct tag, in the style of encoding/json: `pyfmt:"x"` renames a field,
+`pyfmt:"x,omitempty"` skips the field when it holds its zero value, and `pyfmt:"-"` always skips it.
+Setting the Formatter's OmitZeroFields option skips every zero-valued field.
+
+The 'p' type prints structs, maps, slices, arrays, and pointers as an indented tree, with one field,
+item, or map entry per line and map keys sorted. It's helpful for dumping configuration structs or
+decoded JSON. The width sets how many spaces each level is indented by (two by default), and the
+precision sets the maximum depth to print, beyond which containers are elided as '{...}' or '[...]':
+
+  pyfmt.Must("{:4p}", []int{1, 2}) --> "[\n    1,\n    2,\n]"
+
+Pointer cycles are printed as '<cycle>'. The Formatter's PrettyMaxDepth option sets the maximum
+depth when no precision is given, and PrettyMaxItems sets how many items of each collection are
+printed before the rest are elided with '...'.
+
+Encoding Types
+
+pyfmt also has types that encode the value, to avoid having to encode it before formatting:
+
+  'j'         - encode the value as JSON with encoding/json. If a width is given, the JSON is
+                indented by that many spaces per level.
+  'base64'    - encode a []byte or string with standard base64
+  'base64url' - encode a []byte or string with URL-safe base64
+  'hexdump'   - print a []byte or string as a multi-line hex dump, in the same layout as xxd
+
+The base64 types can be combined with fill and alignment, like strings:
+
+  pyfmt.Must("{:*^8base64}", "hi") --> "**aGk=**"
+
+Error Chains
+
+Error values print their Error() message by default. Two types print the chain of errors they wrap
+as well, following their Unwrap() error and Unwrap() []error methods, as errors.Unwrap and
+errors.Join do:
+
+  'chain' - print each error in the chain on one line, joined with " <- "
+  'tree'  - print each error in the chain on its own line, with errors joined by errors.Join as an
+            indented list
+
+Each error is printed without the text it repeats from the error it wraps, so an error made with
+fmt.Errorf("read config: %w", err) is printed as just "read config":
+
+  pyfmt.Must("{:chain}", err) --> "read config <- open app.yaml <- no such file"
+  pyfmt.Must("{:tree}", errors.Join(err1, err2)) --> "- first error\n- second error"
+
+If # is present, each error is prefixed with its Go type, e.g. "*fs.PathError: open x". The
+precision limits how many levels of wrapped errors are printed, with the rest elided as "...". The
+'chain' type can be combined with fill and alignment like a string.
+
+Enums and Flags
+
+Integer types used as enums or bit flags can have names registered for their values, which are
+printed in place of the number when no type, or the 's' type, is given:
+
+  pyfmt.RegisterEnum(map[State]string{Idle: "Idle", Connected: "Connected"})
+  pyfmt.RegisterFlags(map[Mode]string{Read: "READ", Write: "WRITE"})
+
+  pyfmt.Must("{}", Connected) --> "Connected"
+  pyfmt.Must("{:d}", Connected) --> "1"
+  pyfmt.Must("{}", Read|Write|0x40) --> "READ|WRITE|0x40"
+
+Enum values without a name are printed as the type name and number, e.g. "State(7)". Flags are
+printed as the names of their set bits joined with '|', with any bits without a name printed in hex.
+Names are found through pointers and interfaces, so they also apply to struct fields and map values
+reached through field names. The other types, like 'd' and 'x', print the number.
+
+Printf Verbs
+
+For anything the format specifier can't express, a Go printf verb can be used as the format
+specifier, and is passed directly to the fmt package for that one value:
+
+  pyfmt.Must("{name:%q}", map[string]string{"name": "a b"}) --> "\"a b\""
+  pyfmt.Must("{0:%-08.3f}|", 3.14159) --> "3.142   |"
+
+The verb must be a single '%', followed by optional flags, width, and precision, and then a verb
+letter. Argument indexes and '*' widths aren't supported. A malformed verb, or one that can't
+format the value (e.g. %d on a string), is a format error, rather than fmt's "%!d(string=...)"
+output. A lone '%' is still the percent type, and '%' followed by an alignment is still a fill
+character.
+
+Percent Formatting
+
+For porting Python code that uses the older '%' operator, 'PercentFmt' and 'PercentMust' format
+with printf-style conversion specifiers, following Python's rules rather than Go's:
+
+  pyfmt.PercentMust("%s is %d years old", "Bob", 42) --> "Bob is 42 years old"
+  pyfmt.PercentMust("%(Name)s is %(Age)d years old", person) --> "Bob is 42 years old"
+  pyfmt.PercentMust("%-6s|%05.1f|%#x", "ab", 3.14159, 255) --> "ab    |003.1|0xff"
+
+A conversion specifier is a '%', an optional mapping key in parentheses, optional flags from
+'#0- +', an optional width and precision (either of which may be '*' to take it from the
+arguments), an optional ignored length modifier ('h', 'l', or 'L'), and a conversion type from
+'diouxXeEfFgGcrsa%'. A mapping key is looked up in the single argument the same way as a field
+name, except that its first part is always a key, even if it's a number, and it may be a compound
+name like '%(user.Name)s'. 's' formats the value like '{}', while 'r' and 'a' format it as a Python
+literal, like the 'R' type. As in Python, it's an error if there are too few arguments, if any
+positional arguments aren't used, or if a conversion without a mapping key follows one with a key. 'd', 'i', and 'u' truncate
+floats towards zero, while 'o', 'x', and 'X' require an integer.
+
+Template Substitution
+
+'Substitute' and 'SafeSubstitute' are the equivalent of Python's string.Template, for simpler
+templates where the values don't need formatting. "$name" and "${name}" are replaced by the value
+the name refers to, and "$$" is replaced by a single '$'. Names are looked up in the arguments like
+field names, and placeholders in braces may be compound field names:
+
+  pyfmt.Substitute("$user exceeded ${quota}GB", map[string]interface{}{"user": "ann", "quota": 5})
+    --> "ann exceeded 5GB"
+  pyfmt.Substitute("${user.Name} exceeded ${user.Quota}GB", map[string]User{"user": u})
+    --> "ann exceeded 5GB"
+
+'Substitute' errors if a name can't be found, or if a delimiter isn't followed by a valid
+placeholder. 'SafeSubstitute' leaves those placeholders in the output unchanged instead.
+
+The delimiter, and the regular expressions matching names with and without braces, can be changed
+by creating a Template with those options:
+
+  t := &pyfmt.Template{Template: "%user exceeded %{quota}GB", Delimiter: "%"}
+  t.Substitute(vars) --> "ann exceeded 5GB"
+
 Custom formatters
 
 Internally, pyfmt uses Go's fmt package, so existing types satisfying its Formatter, GoStringer,
@@ -158,6 +438,99 @@ sub-field that has a custom formatter, the struct field must be exported for pyf
 custom Formatter. This is similar to the default 'fmt' package, which doesn't apply custom Stringer
 implementations to unexported struct fields.
 
+Formatter options
+
+The package-level functions use the default options. To change them, create a Formatter, which has
+'Fmt', 'Must', and 'Error' methods that act like the package-level functions:
+
+  py := &pyfmt.Formatter{PyLiterals: true}
+  py.Must("{}", []string{"a", "b"}) --> "['a', 'b']"
+
+The zero Formatter behaves exactly like the package-level functions.
+
+Nil-safe field names
+
+By default, a field name that goes through a nil pointer is an error. Setting NilSafe on a
+Formatter prints NilPlaceholder ("<nil>" if empty) instead, for any field name that goes through a
+nil pointer, map, interface, or slice, and for nil values and nil pointers. The placeholder is
+printed as a string, whatever the format type, but is still aligned and padded:
+
+  safe := &pyfmt.Formatter{NilSafe: true, NilPlaceholder: "None"}
+  safe.Must("{req.User.Profile.Name:>6}", vars) --> "  None"
+
+Whether or not a Formatter is nil-safe, pointers to bools, numbers, and strings are dereferenced
+before formatting, so the format spec applies to the value rather than its address, e.g.
+"{:.2f}" formats a *float64 like a float64. Pointers with their own String, Format, Error,
+MarshalText, or Value methods are formatted with those instead.
+
+Missing keys and defaults
+
+A field name may end with a default after a '|', which is printed in place of a value that can't
+be found, and is formatted with the field's format spec like any other string:
+
+  pyfmt.Must("Hi {nickname|anonymous:>10}", map[string]string{}) --> "Hi  anonymous"
+
+Since the format spec starts at the first ':', the default can't contain a ':'. Only values that
+can't be found are replaced: missing arguments, map keys, and struct fields, indexes out of range,
+nil pointers along the way, and errors returned by ItemGetters, AttrGetters, and the Named method
+of Args. Malformed field names, and errors from methods, lazy arguments, and format specs are
+always returned.
+
+Names may contain a '|' too, so the whole name is looked up first, and a '|' outside of brackets
+only starts a default if that fails. "{a|b}" prints the map key "a|b" if there is one, and the key
+"a", or else "b", otherwise. To look up a key containing a '|' with a default, put it in brackets,
+like "{0[a|b]|none}", or "{[a|b]|none}" with automatic field numbering.
+
+Fields without a default that can't be found are an error, unless MissingKey is set on the
+Formatter: MissingKeyEmpty prints nothing, MissingKeyMarker prints "<missing:name>", and
+MissingKeyKeep leaves the format item in the output as it was written, so that it can be filled in
+by a later pass:
+
+  keep := &pyfmt.Formatter{MissingKey: pyfmt.MissingKeyKeep}
+  keep.Must("{greeting}, {name:>6}", map[string]string{"greeting": "Hi"}) --> "Hi, {name:>6}"
+
+MissingKey applies to Fmt and FmtArgs, but not to PercentFmt or Template, which have their own
+handling of missing names.
+
+Delimiters
+
+For text that's full of literal braces, like JSON or source code, LeftDelim and RightDelim change
+the delimiters of format items, and LeftEscape and RightEscape change the sequences that output a
+literal delimiter, which default to the delimiter doubled:
+
+  gen := &pyfmt.Formatter{LeftDelim: "<<", RightDelim: ">>"}
+  gen.Must(`{"id": <<>>, "tags": {}}`, 7) --> `{"id": 7, "tags": {}}`
+
+As with braces, an unescaped closing delimiter outside of a format item is an error, so delimiters
+that don't otherwise appear in the text work best.
+
+Python literals
+
+Setting PyLiterals renders values the way Python would print the equivalent Python value, which is
+helpful when templates are shared with Python code. Bools are printed as True and False (or 1 and 0
+with the integer and float types), nil is printed as None, slices and arrays are printed as lists
+(['a', 'b']), []byte as bytes (b'hi'), and maps as dicts ({'a': 1}), with map keys sorted. Values
+inside containers are printed with Python's repr() rules, so strings are quoted and escaped.
+Containers that contain themselves are printed as [...] like in Python. Values that have their own
+String, Error, or Format method are printed with that method.
+
+TextMarshalers and Valuers
+
+Many types implement encoding.TextMarshaler (IDs, enums, addresses) or database/sql/driver.Valuer
+(sql.NullString, sql.NullInt64) without implementing fmt.Stringer, and so print as their raw struct
+fields. Two Formatter options make pyfmt use these interfaces:
+
+  f := &pyfmt.Formatter{Valuers: true, TextMarshalers: true, NullText: "NULL"}
+  f.Must("{:03d}", sql.NullInt64{Int64: 7, Valid: true}) --> "007"
+  f.Must("{:>6}", sql.NullInt64{}) --> "  NULL"
+
+With Valuers set, a driver.Valuer is replaced with the value it holds before the format specifier
+is applied, so the numeric types apply to the underlying numbers. A Valuer holding nil is printed
+as NullText, or like any other nil if NullText is empty. With TextMarshalers set, a value
+implementing encoding.TextMarshaler is printed using MarshalText when no type, or the 's' type, is
+given. Valuers are unwrapped before TextMarshalers are checked. Neither applies to the 'r' or 't'
+types, and PyFormatter takes precedence over both.
+
 TODOs
 
   *  Improve performance. Some of the string manipulations allocate more frequency than they need
@@ -0,0 +1,123 @@
+package pyfmt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bytesOf returns the contents of a []byte or string value, including named types of those
+// kinds, or false if the value is neither.
+func bytesOf(val interface{}) ([]byte, bool) {
+	v := valueOf(val)
+	if !v.IsValid() {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), true
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), true
+		}
+	}
+	return nil, false
+}
+
+// renderJSON renders the value with encoding/json. If a width is given, the output is indented by
+// that many spaces per level.
+func (r *render) renderJSON() error {
+	v := valueOf(r.val)
+	if v.IsValid() && !v.CanInterface() {
+		return Error("cannot encode unexported value as JSON: {}", v.Type())
+	}
+	var b buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if r.minWidth != "" {
+		width, err := strconv.Atoi(r.minWidth)
+		if err != nil {
+			return Error("Can't convert width {} to int", r.minWidth)
+		}
+		enc.SetIndent("", strings.Repeat(" ", width))
+	}
+	var err error
+	if v.IsValid() {
+		err = enc.Encode(v.Interface())
+	} else {
+		err = enc.Encode(nil)
+	}
+	if err != nil {
+		return Error("error encoding JSON: {}", err)
+	}
+	// Encode always adds a trailing newline.
+	r.buf.Write(b.contents[:len(b.contents)-1])
+	return nil
+}
+
+// renderBase64 replaces the value being rendered with its base64 encoding, so that it can then be
+// aligned like any other string.
+func (r *render) renderBase64() error {
+	data, ok := bytesOf(r.val)
+	if !ok {
+		return Error("{} format requires a []byte or string, got {:t}", r.renderVerb, r.val)
+	}
+	if r.renderVerb == "base64url" {
+		r.val = base64.URLEncoding.EncodeToString(data)
+	} else {
+		r.val = base64.StdEncoding.EncodeToString(data)
+	}
+	r.renderVerb = "v"
+	return nil
+}
+
+const hexdumpWidth = 16
+
+// renderHexdump renders a []byte or string as a multi-line hex dump in the style of xxd: an offset,
+// sixteen bytes as hex in groups of two, and those bytes as ASCII.
+func (r *render) renderHexdump() error {
+	data, ok := bytesOf(r.val)
+	if !ok {
+		return Error("hexdump format requires a []byte or string, got {:t}", r.val)
+	}
+	const hexdigits = "0123456789abcdef"
+	line := make([]byte, 0, 68)
+	for offset := 0; offset < len(data); offset += hexdumpWidth {
+		chunk := data[offset:]
+		if len(chunk) > hexdumpWidth {
+			chunk = chunk[:hexdumpWidth]
+		}
+		line = line[:0]
+		if offset > 0 {
+			line = append(line, '\n')
+		}
+		for shift := uint(28); ; shift -= 4 {
+			line = append(line, hexdigits[(offset>>shift)&0xf])
+			if shift == 0 {
+				break
+			}
+		}
+		line = append(line, ':')
+		for i := 0; i < hexdumpWidth; i++ {
+			if i%2 == 0 {
+				line = append(line, ' ')
+			}
+			if i < len(chunk) {
+				line = append(line, hexdigits[chunk[i]>>4], hexdigits[chunk[i]&0xf])
+			} else {
+				line = append(line, ' ', ' ')
+			}
+		}
+		line = append(line, ' ', ' ')
+		for _, c := range chunk {
+			if c < ' ' || c > '~' {
+				c = '.'
+			}
+			line = append(line, c)
+		}
+		r.buf.Write(line)
+	}
+	return nil
+}
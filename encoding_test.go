@@ -0,0 +1,69 @@
+package pyfmt
+
+import (
+	"testing"
+)
+
+func TestEncodingFormat(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+		Tags []string
+	}
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		// JSON
+		{"{:j}", payload{"a<b>", []string{"x"}}, `{"name":"a<b>","Tags":["x"]}`},
+		{"{:2j}", payload{"a", nil}, "{\n  \"name\": \"a\",\n  \"Tags\": null\n}"},
+		{"{:j}", "str", `"str"`},
+		{"{:j}", nil, "null"},
+		{"{:j}", map[string]int{"b": 1, "a": 2}, `{"a":2,"b":1}`},
+
+		// Base64
+		{"{:base64}", []byte("hello?>"), "aGVsbG8/Pg=="},
+		{"{:base64url}", []byte("hello?>"), "aGVsbG8_Pg=="},
+		{"{:base64}", "hi", "aGk="},
+		{"{:>8base64}", "hi", "    aGk="},
+		{"{:*^8base64}", "hi", "**aGk=**"},
+		{"{0[data]:base64}", map[string][]byte{"data": {0xff}}, "/w=="},
+
+		// Hex dumps
+		{"{:hexdump}", []byte{}, ""},
+		{"{:hexdump}", "Hello, World!\n", "00000000: 4865 6c6c 6f2c 2057 6f72 6c64 210a       Hello, World!."},
+		{"{:hexdump}", []byte("Hello, World! this is longer\x00\x01"),
+			"00000000: 4865 6c6c 6f2c 2057 6f72 6c64 2120 7468  Hello, World! th\n" +
+				"00000010: 6973 2069 7320 6c6f 6e67 6572 0001       is is longer.."},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1:r}, Want: {want:r}", test, got))
+		}
+	}
+}
+
+func TestEncodingFormatError(t *testing.T) {
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+	}{
+		{"{:base64}", 42},
+		{"{:hexdump}", []int{1}},
+		{"{:j}", make(chan int)},
+		{"{a:j}", struct{ a []int }{}},
+		{"{:base64x}", "a"},
+	}
+
+	for _, test := range tests {
+		_, err := Fmt(test.fmtStr, test.param)
+		if err == nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) did not error when expected!", test))
+		}
+	}
+}
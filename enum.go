@@ -0,0 +1,156 @@
+package pyfmt
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// enumNames holds the names registered for the values of an integer type.
+type enumNames struct {
+	flags bool
+	// values and names are sorted by the value's bits.
+	values []uint64
+	names  []string
+}
+
+// enumRegistry maps integer types to their registered names.
+type enumRegistry map[reflect.Type]*enumNames
+
+var (
+	// enumsMu serializes registrations, which replace enums with an updated copy, so that looking
+	// up names, which happens for every value rendered, doesn't need a lock.
+	enumsMu sync.Mutex
+	enums   atomic.Value
+)
+
+// RegisterEnum registers names for the values of an integer type, which are then printed in place
+// of the numbers when no type, or the 's' type, is given. It takes a map from values of the type to
+// their names, e.g.
+//
+//   pyfmt.RegisterEnum(map[State]string{Idle: "Idle", Connected: "Connected"})
+//
+// Values without a name are printed as the type name and number, e.g. State(7). Panics if names
+// isn't a map from an integer type to strings.
+func RegisterEnum(names interface{}) {
+	registerNames(names, false)
+}
+
+// RegisterFlags is like RegisterEnum, but for bit flags. Values are printed as the names of their
+// set bits joined with '|', e.g. READ|WRITE, and any set bits without a name are printed in hex,
+// e.g. READ|0x40. The map may also name the zero value, and values with more than one bit set.
+func RegisterFlags(names interface{}) {
+	registerNames(names, true)
+}
+
+func registerNames(names interface{}, flags bool) {
+	m := reflect.ValueOf(names)
+	if m.Kind() != reflect.Map || m.Type().Elem().Kind() != reflect.String || !isInteger(m.Type().Key().Kind()) {
+		panic(Must("pyfmt: names must be a map from an integer type to strings, got {:t}", names))
+	}
+	e := &enumNames{flags: flags}
+	for _, key := range m.MapKeys() {
+		e.values = append(e.values, integerBits(key))
+		e.names = append(e.names, m.MapIndex(key).String())
+	}
+	sort.Sort(e)
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	old, _ := enums.Load().(enumRegistry)
+	registry := make(enumRegistry, len(old)+1)
+	for t, names := range old {
+		registry[t] = names
+	}
+	registry[m.Type().Key()] = e
+	enums.Store(registry)
+}
+
+func (e *enumNames) Len() int           { return len(e.values) }
+func (e *enumNames) Less(i, j int) bool { return e.values[i] < e.values[j] }
+func (e *enumNames) Swap(i, j int) {
+	e.values[i], e.values[j] = e.values[j], e.values[i]
+	e.names[i], e.names[j] = e.names[j], e.names[i]
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// integerBits returns the bits of an integer value, truncated to the size of its type.
+func integerBits(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := uint(v.Type().Bits())
+		return uint64(v.Int()) & (^uint64(0) >> (64 - bits))
+	}
+	return v.Uint()
+}
+
+// lookupEnum returns the registered names for a value's type, following pointers and interfaces
+// to find the integer they hold.
+func lookupEnum(val interface{}) (*enumNames, reflect.Value) {
+	registry, _ := enums.Load().(enumRegistry)
+	if len(registry) == 0 {
+		return nil, reflect.Value{}
+	}
+	v := valueOf(val)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || !isInteger(v.Kind()) {
+		return nil, v
+	}
+	return registry[v.Type()], v
+}
+
+// name returns the name of a value of the type.
+func (e *enumNames) name(v reflect.Value) string {
+	bits := integerBits(v)
+	i := sort.Search(len(e.values), func(i int) bool { return e.values[i] >= bits })
+	if i < len(e.values) && e.values[i] == bits {
+		return e.names[i]
+	}
+	if !e.flags {
+		var n string
+		if v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr {
+			n = strconv.FormatUint(v.Uint(), 10)
+		} else {
+			n = strconv.FormatInt(v.Int(), 10)
+		}
+		return v.Type().Name() + "(" + n + ")"
+	}
+	var names []string
+	remaining := bits
+	// Flags are matched from the smallest value up, so single bits are matched before any values
+	// combining them.
+	for i, value := range e.values {
+		if value != 0 && remaining&value == value {
+			names = append(names, e.names[i])
+			remaining &^= value
+		}
+	}
+	if remaining != 0 || len(names) == 0 {
+		names = append(names, "0x"+strconv.FormatUint(remaining, 16))
+	}
+	return strings.Join(names, "|")
+}
+
+// renderEnum replaces values of types registered with RegisterEnum or RegisterFlags with their
+// names, so that they can be aligned like any other string. Only applies when no type, or the 's'
+// type, is given, so other types print the number.
+func (r *render) renderEnum() {
+	if r.renderVerb != "v" && r.renderVerb != "+v" {
+		return
+	}
+	if e, v := lookupEnum(r.val); e != nil {
+		r.val = e.name(v)
+	}
+}
@@ -0,0 +1,91 @@
+package pyfmt
+
+import (
+	"testing"
+)
+
+type connState int
+
+const (
+	idle connState = iota
+	connected
+	closed
+)
+
+type fileMode uint8
+
+const (
+	modeRead fileMode = 1 << iota
+	modeWrite
+	modeExec
+)
+
+type signedFlags int8
+
+func init() {
+	RegisterEnum(map[connState]string{idle: "Idle", connected: "Connected", closed: "Closed"})
+	RegisterFlags(map[fileMode]string{0: "NONE", modeRead: "READ", modeWrite: "WRITE", modeExec: "EXEC"})
+	RegisterFlags(map[signedFlags]string{1: "LOW", -128: "HIGH"})
+}
+
+func TestEnumFormat(t *testing.T) {
+	state := connected
+	var statePtr *connState
+	type conn struct {
+		State *connState
+		Mode  interface{}
+		state connState
+	}
+
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{}", connected, "Connected"},
+		{"{:s}", closed, "Closed"},
+		{"{:>10}", idle, "      Idle"},
+		{"{:d}", connected, "1"},
+		{"{:03d}", closed, "002"},
+		{"{:r}", connected, "1"},
+		{"{}", connState(7), "connState(7)"},
+		{"{}", connState(-1), "connState(-1)"},
+		{"{}", &state, "Connected"},
+		{"{}", statePtr, "<nil>"},
+		{"{State}", conn{State: &state}, "Connected"},
+		{"{Mode}", conn{Mode: modeRead | modeWrite}, "READ|WRITE"},
+		{"{state}", conn{state: closed}, "Closed"},
+		{"{0[0]}", []interface{}{connected}, "Connected"},
+
+		{"{}", modeRead, "READ"},
+		{"{}", modeRead | modeWrite, "READ|WRITE"},
+		{"{}", modeRead | modeWrite | 0x40, "READ|WRITE|0x40"},
+		{"{}", fileMode(0x80), "0x80"},
+		{"{}", fileMode(0), "NONE"},
+		{"{:#x}", modeRead | modeExec, "0x5"},
+		{"{}", signedFlags(-127), "LOW|HIGH"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param:r}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param:r}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestRegisterEnumPanics(t *testing.T) {
+	for _, names := range []interface{}{map[string]string{}, map[int]int{}, []string{}} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error(Must("RegisterEnum({:t}) did not panic", names))
+				}
+			}()
+			RegisterEnum(names)
+		}()
+	}
+}
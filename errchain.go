@@ -0,0 +1,175 @@
+package pyfmt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// chainSeparator separates the errors in a chain printed with the 'chain' type.
+const chainSeparator = " <- "
+
+// errWriter writes the chain of errors wrapped by an error.
+type errWriter struct {
+	buf *buffer
+	// showType prefixes each error's message with its Go type.
+	showType bool
+	// maxDepth limits how many levels of wrapped errors are written, zero means no limit.
+	maxDepth int
+}
+
+// renderErrorChain renders an error and the errors it wraps. With the 'chain' type, they're joined
+// on one line and can be aligned like a string; with the 'tree' type, each is on its own line and
+// errors joined with errors.Join are written as an indented list.
+func (r *render) renderErrorChain() error {
+	err, ok := r.val.(error)
+	if !ok || isNilPointer(r.val) {
+		return Error("{} format requires a non-nil error, got {:t}", r.renderVerb, r.val)
+	}
+	w := errWriter{buf: r.buf, showType: r.showRadix}
+	if r.precision != "" {
+		depth, perr := strconv.Atoi(r.precision[1:])
+		if perr != nil {
+			return Error("Can't convert precision {} to int", r.precision)
+		}
+		w.maxDepth = depth
+	}
+	if r.renderVerb == "tree" {
+		w.writeTree(err, "", 0)
+		return nil
+	}
+	var b buffer
+	w.buf = &b
+	w.writeChain(err, 0)
+	r.val = string(b.contents)
+	r.renderVerb = "v"
+	r.precision = ""
+	r.showRadix = false
+	return nil
+}
+
+// unwrapError returns the error directly wrapped by err, or the errors it wraps if it wraps more
+// than one, like those made by errors.Join, leaving out any nil errors. The Unwrap methods are
+// checked directly, as errors.Unwrap does, so that older versions of Go are supported.
+func unwrapError(err error) (error, []error) {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		var multi []error
+		for _, child := range e.Unwrap() {
+			if child != nil {
+				multi = append(multi, child)
+			}
+		}
+		return nil, multi
+	case interface{ Unwrap() error }:
+		return e.Unwrap(), nil
+	}
+	return nil, nil
+}
+
+// message returns the part of an error's message that isn't repeated from the errors it wraps. For
+// errors made with fmt.Errorf("context: %w", err), this is just "context".
+func (w *errWriter) message(err error) string {
+	msg := err.Error()
+	next, multi := unwrapError(err)
+	if next != nil {
+		msg = strings.TrimSuffix(msg, ": "+next.Error())
+	} else if len(multi) > 0 {
+		joined := make([]string, 0, len(multi))
+		for _, e := range multi {
+			joined = append(joined, e.Error())
+		}
+		if msg == strings.Join(joined, "\n") {
+			// errors.Join has no message of its own.
+			return ""
+		}
+		msg = strings.TrimSuffix(msg, ": "+strings.Join(joined, "\n"))
+	}
+	if w.showType {
+		return fmt.Sprintf("%T: %s", err, msg)
+	}
+	return msg
+}
+
+// limited returns true if an error at this depth is past the maximum depth.
+func (w *errWriter) limited(depth int) bool {
+	return w.maxDepth > 0 && depth >= w.maxDepth
+}
+
+func (w *errWriter) writeChain(err error, depth int) {
+	sep := false
+	for ; err != nil; depth++ {
+		if w.limited(depth) {
+			if sep {
+				w.buf.WriteString(chainSeparator)
+			}
+			w.buf.WriteString("...")
+			return
+		}
+		next, multi := unwrapError(err)
+		childDepth := depth
+		if msg := w.message(err); msg != "" || len(multi) == 0 {
+			if sep {
+				w.buf.WriteString(chainSeparator)
+			}
+			w.buf.WriteString(msg)
+			sep = true
+			childDepth++
+		}
+		if len(multi) > 0 {
+			if sep {
+				w.buf.WriteString(chainSeparator)
+			}
+			w.buf.WriteString("[")
+			for i, e := range multi {
+				if i > 0 {
+					w.buf.WriteString(", ")
+				}
+				w.writeChain(e, childDepth)
+			}
+			w.buf.WriteString("]")
+			return
+		}
+		err = next
+	}
+}
+
+// writeTree writes an error and the errors it wraps one per line, starting each line with indent.
+// The first line isn't preceded by a newline.
+func (w *errWriter) writeTree(err error, indent string, depth int) {
+	for first := true; err != nil; depth++ {
+		if !first {
+			w.buf.WriteString("\n")
+		}
+		first = false
+		w.buf.WriteString(indent)
+		if w.limited(depth) {
+			w.buf.WriteString("...")
+			return
+		}
+		next, multi := unwrapError(err)
+		childDepth := depth
+		if msg := w.message(err); msg != "" || len(multi) == 0 {
+			w.buf.WriteString(msg)
+			childDepth++
+			if len(multi) > 0 {
+				w.buf.WriteString("\n")
+				w.buf.WriteString(indent)
+			}
+		}
+		for i, e := range multi {
+			if i > 0 {
+				w.buf.WriteString("\n")
+				w.buf.WriteString(indent)
+			}
+			// The first line of each joined error follows the bullet, and the rest line up with it.
+			w.buf.WriteString("- ")
+			var sub buffer
+			subWriter := *w
+			subWriter.buf = &sub
+			subWriter.writeTree(e, indent+"  ", childDepth)
+			w.buf.Write(sub.contents[len(indent)+2:])
+		}
+		err = next
+	}
+}
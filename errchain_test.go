@@ -0,0 +1,117 @@
+package pyfmt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string {
+	return Must("code {}", e.code)
+}
+
+// wrapError wraps an error with context, like fmt.Errorf("context: %w", err) does, which isn't
+// available before Go 1.13.
+type wrapError struct {
+	msg string
+	err error
+}
+
+func wrap(msg string, err error) error {
+	return &wrapError{msg, err}
+}
+
+func (e *wrapError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *wrapError) Unwrap() error {
+	return e.err
+}
+
+// joinError joins errors, like errors.Join does, which isn't available before Go 1.20.
+type joinError []error
+
+func join(errs ...error) error {
+	return joinError(errs)
+}
+
+func (e joinError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e joinError) Unwrap() []error {
+	return e
+}
+
+// multiError wraps several errors, some of which may be nil, with a message of its own.
+type multiError []error
+
+func (e multiError) Error() string {
+	return "multi"
+}
+
+func (e multiError) Unwrap() []error {
+	return e
+}
+
+func TestErrorChainFormat(t *testing.T) {
+	root := errors.New("no such file")
+	wrapped := wrap("read config", wrap("open app.yaml", root))
+	joined := wrap("startup", join(wrapped, &codeError{3}))
+
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{:chain}", root, "no such file"},
+		{"{:chain}", wrapped, "read config <- open app.yaml <- no such file"},
+		{"{:.2chain}", wrapped, "read config <- open app.yaml <- ..."},
+		{"{:>20.1chain}", wrapped, "  read config <- ..."},
+		{"{:#chain}", wrap("loading", wrap("open x", root)),
+			"*pyfmt.wrapError: loading <- *pyfmt.wrapError: open x <- *errors.errorString: no such file"},
+		{"{:chain}", joined, "startup <- [read config <- open app.yaml <- no such file, code 3]"},
+		{"{:chain}", join(root, &codeError{1}), "[no such file, code 1]"},
+		{"{:tree}", wrapped, "read config\nopen app.yaml\nno such file"},
+		{"{:.1tree}", wrapped, "read config\n..."},
+		{"{:tree}", joined, "startup\n- read config\n  open app.yaml\n  no such file\n- code 3"},
+		{"{:tree}", join(join(root, root), &codeError{2}),
+			"- - no such file\n  - no such file\n- code 2"},
+		{"{:#tree}", join(&codeError{1}), "- *pyfmt.codeError: code 1"},
+		{"{err:chain}", map[string]error{"err": wrapped}, "read config <- open app.yaml <- no such file"},
+		{"{:chain}", multiError{root, nil}, "multi <- [no such file]"},
+		{"{:tree}", multiError{root, nil}, "multi\n- no such file"},
+		{"{:tree}", multiError{nil, root, nil, &codeError{4}}, "multi\n- no such file\n- code 4"},
+		{"{:chain}", multiError{nil}, "multi"},
+		{"{:tree}", multiError{nil}, "multi"},
+		{"{}", wrapped, "read config: open app.yaml: no such file"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1:r}, Want: {want:r}", test, got))
+		}
+	}
+}
+
+func TestErrorChainFormatError(t *testing.T) {
+	var nilErr *codeError
+	for _, param := range []interface{}{"not an error", nil, nilErr} {
+		if _, err := Fmt("{:chain}", param); err == nil {
+			t.Error(Must("Fmt({{:chain}}, {:r}) did not error when expected!", param))
+		}
+	}
+}
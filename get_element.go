@@ -1,8 +1,11 @@
 package pyfmt
 
 import (
+	"encoding"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 // getElement takes a string, an offset, and a list of elements, and returns the element in the
@@ -17,159 +20,680 @@ import (
 //   '[{id}]' to look up the name as above,
 // - if the 'name' contains a '.' use the above lookup rules on the part before the dot to look up
 //   an element, and then follow the rules as above.
+// - if a part of the name doesn't match a field, key, or index, but does match an exported method
+//   taking no arguments, the method is called, unless the Formatter disables methods.
+// - values implementing ItemGetter or AttrGetter look up the parts of the name themselves.
 func getElement(name string, offset int, elems ...interface{}) (interface{}, error) {
-	if len(elems) == 0 {
-		return nil, Error("attempted to fetch {}/{} from empty list", name, offset)
-	}
+	return std.getElement(name, offset, elems...)
+}
+
+// getElement is like the package-level getElement, but uses the Formatter's options.
+func (fm *Formatter) getElement(name string, offset int, elems ...interface{}) (interface{}, error) {
+	return fm.lookupArg(name, offset, &valueArgs{elems}, nil)
+}
+
+// lookupArg is like getElement, but looks up the first part of the name in an Args. LazyValues
+// found along the way are computed, and cached in lazy.
+func (fm *Formatter) lookupArg(name string, offset int, args Args, lazy *lazyCache) (interface{}, error) {
 	field, remainder, err := splitName(name, true)
 	if err != nil {
 		return nil, err
 	}
 
-	val := elems[0]
-	found := false
+	var val interface{}
 	if field == "" {
-		if offset < len(elems) {
-			val = elems[offset]
-			found = true
-		} else {
-			return nil, Error("too large offset: {}", offset)
+		if offset >= args.Len() {
+			return nil, missingError{Error("too large offset: {}", offset)}
 		}
-	} else if parse, err := strconv.ParseUint(field, 10, 64); err == nil {
-		if parse < uint64(len(elems)) {
-			val = elems[parse]
-			found = true
-		} else {
-			return nil, Error("index out of bounds: {}", parse)
+		val, err = args.Positional(offset)
+	} else if parse, perr := strconv.ParseUint(field, 10, 64); perr == nil {
+		if parse >= uint64(args.Len()) {
+			return nil, missingError{Error("index out of bounds: {}", parse)}
 		}
+		val, err = args.Positional(int(parse))
+	} else if withFormatter, ok := args.(formatterArgs); ok {
+		val, err = withFormatter.namedWith(fm, field)
+	} else if val, err = args.Named(field); err != nil {
+		err = missingError{err}
+	}
+	if err != nil {
+		return nil, checkName(remainder, err)
 	}
+	return fm.lookupPath(val, remainder, lazy)
+}
 
-	if !found {
-		val, err = elementByName(field, val)
-		if err != nil {
-			return nil, err
-		}
+// lookupKey is like lookupArg, but looks up the first part of the name in the first element, even
+// if it's a number, like the mapping key of Python's "%(key)s".
+func (fm *Formatter) lookupKey(name string, elems []interface{}, lazy *lazyCache) (interface{}, error) {
+	field, remainder, err := splitName(name, true)
+	if err != nil {
+		return nil, err
+	}
+	val, err := fm.namedValue(elems, field)
+	if err != nil {
+		return nil, checkName(remainder, err)
+	}
+	return fm.lookupPath(val, remainder, lazy)
+}
+
+// lookupPath looks up the rest of a field name, after its first part, in the value of the first
+// part.
+func (fm *Formatter) lookupPath(val interface{}, remainder string, lazy *lazyCache) (interface{}, error) {
+	val, err := lazy.resolve(val)
+	if err != nil {
+		return nil, err
 	}
 	for remainder != "" {
+		item := strings.HasPrefix(remainder, "[")
+		var field string
 		field, remainder, err = splitName(remainder, false)
 		if err != nil {
 			return nil, err
 		}
-		val, err = elementByName(field, val)
+		if fm.NilSafe && isNil(val) {
+			return nilPath{}, checkName(remainder, nil)
+		}
+		val, err = fm.elementByName(field, item, val)
 		if err != nil {
+			return nil, checkName(remainder, err)
+		}
+		if val, err = lazy.resolve(val); err != nil {
 			return nil, err
 		}
 	}
 	return val, nil
 }
 
+// checkName returns an error if the rest of a field name, which wasn't looked up because looking
+// up an earlier part of it failed, is malformed, so that a malformed name is always reported rather
+// than being replaced by a default. Otherwise returns err.
+func checkName(remainder string, err error) error {
+	for remainder != "" {
+		var serr error
+		if _, remainder, serr = splitName(remainder, false); serr != nil {
+			return serr
+		}
+	}
+	return err
+}
+
+// namedValue gets a named argument from a list of arguments, by looking it up in the first one,
+// which is usually a struct or a map.
+func (fm *Formatter) namedValue(elems []interface{}, name string) (interface{}, error) {
+	if len(elems) == 0 {
+		return nil, missingError{Error("attempted to fetch {} from empty list", name)}
+	}
+	if fm.NilSafe && isNil(elems[0]) {
+		return nilPath{}, nil
+	}
+	return fm.elementByName(name, true, elems[0])
+}
+
 // splitName splits the first subfield off of the name, returning both the that was split off and
 // the remainder. Errors if it can't be split.  Note that this does treat test[foo].bar and
 // test.bar[foo] as being interchangeable. This normally makes sense, especially for
 // structs-of-structs and maps-of-maps, but may be somewhat strange for lists of lists, where
 // a[5][6] can be written a.5.6.
 func splitName(name string, first bool) (string, string, error) {
-	end := len(name)
-	foundOpen := false
-	for i := 0; i < end; {
-		cachei := i
-		for i < end && !(name[i] == '[' || name[i] == '.' || name[i] == ']') {
-			i++
-		}
-		if i < end {
-			if name[i] == '.' {
-				return name[:i], name[(i + 1):], nil
-			}
-			if name[i] == ']' && foundOpen {
-				if i+1 < end && !(name[i+1] == '[' || name[i+1] == '.') {
-					return "", "", Error("must begin a new subfield after a closing bracket in {}", name)
-				}
-				if i+1 < end {
-					return name[cachei:i], name[(i + 2):], nil
-				}
-				return name[cachei:i], name[(i + 1):], nil
+	if !first && strings.HasPrefix(name, "[") {
+		var key string
+		var end int
+		if len(name) > 1 && (name[1] == '"' || name[1] == '\'') {
+			// A quoted key may contain any character, including brackets.
+			k, n, err := unquoteKey(name[1:])
+			if err != nil {
+				return "", "", Error("{} in {}", err, name)
 			}
-			if name[i] == ']' && !foundOpen {
-				return "", "", Error("unmatched ] in {}", name)
+			key, end = k, n+1
+			if end >= len(name) || name[end] != ']' {
+				return "", "", Error("expected ] after quoted key in {}", name)
 			}
-			if name[i] == '[' && foundOpen {
+		} else {
+			// Everything up to the closing bracket is the key, so keys may contain '.' and ':'.
+			end = strings.IndexByte(name, ']')
+			if end < 0 || strings.IndexByte(name[1:end], '[') >= 0 {
 				return "", "", Error("unmatched [ in {}", name)
 			}
-			if name[i] == '[' && i == 0 && !first {
-				foundOpen = true
-				i++
-				cachei = i
-				continue
-			}
-			if name[i] == '[' {
-				return name[:i], name[i:], nil
-			}
-		} else {
-			return name, "", nil
+			key = name[1:end]
 		}
-		i++
+		rem := name[end+1:]
+		switch {
+		case rem == "":
+		case rem[0] == '.':
+			rem = rem[1:]
+		case rem[0] != '[':
+			return "", "", Error("must begin a new subfield after a closing bracket in {}", name)
+		}
+		return key, rem, nil
 	}
-	if foundOpen {
-		return "", "", Error("unmatched [ in {}", name)
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '.':
+			return name[:i], name[(i + 1):], nil
+		case '[':
+			return name[:i], name[i:], nil
+		case ']':
+			return "", "", Error("unmatched ] in {}", name)
+		}
 	}
 	return name, "", nil
 }
 
+// unquoteKey unquotes a key that starts with a single or double quote, in which a backslash
+// escapes the character after it. Returns the key, and the length of the quoted key.
+func unquoteKey(s string) (string, int, error) {
+	quote := s[0]
+	var key []byte
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, Error("unterminated quoted key")
+			}
+			key = append(key, s[i])
+		case quote:
+			return string(key), i + 1, nil
+		default:
+			key = append(key, s[i])
+		}
+	}
+	return "", 0, Error("unterminated quoted key")
+}
+
 // elementByName will get the element by name if it's a struct or map, the an element by number
 // from an Array or Slice, and error out otherwise. If possible, will return an interface{} value,
-// but may return a reflect.Value if it cannot be interfaced (e.g., for unexported struct fields)
-func elementByName(name string, src interface{}) (interface{}, error) {
-	var srcVal reflect.Value
-	switch src.(type) {
-	case reflect.Value:
-		srcVal = src.(reflect.Value)
-	default:
-		srcVal = reflect.ValueOf(src)
+// but may return a reflect.Value if it cannot be interfaced (e.g., for unexported struct fields).
+// Values implementing ItemGetter or AttrGetter get the element themselves, and item says which is
+// preferred. If there's no element with the name, falls back to calling a method with the name.
+func (fm *Formatter) elementByName(name string, item bool, src interface{}) (interface{}, error) {
+	if val, ok, err := getFromGetter(name, item, src); ok {
+		return val, err
+	}
+	val, err := fm.fieldByName(name, src)
+	if err != nil && !fm.DisableMethods {
+		if method, ok := methodByName(name, src); ok {
+			return callMethod(name, method)
+		}
+	}
+	return val, err
+}
+
+// ItemGetter is an interface implemented by types that look up the "[key]" parts of field names
+// themselves, like Python's __getitem__, instead of pyfmt using reflection. Useful for types that
+// can't be walked by reflection, like dynamic config objects or lazily-loaded records. An error
+// means the key can't be found, so it may be replaced by a default or a MissingKeyPolicy.
+type ItemGetter interface {
+	PyGetItem(key string) (interface{}, error)
+}
+
+// AttrGetter is like ItemGetter, but for the ".name" parts of field names, like Python's
+// __getattr__.
+type AttrGetter interface {
+	PyGetAttr(name string) (interface{}, error)
+}
+
+// getFromGetter gets an element from a value implementing ItemGetter or AttrGetter, preferring
+// PyGetItem for "[key]" parts of the field name, and PyGetAttr for ".name" parts, but using
+// whichever the value implements. The first part of a field name counts as a key. Returns false
+// if the value implements neither.
+func getFromGetter(name string, item bool, src interface{}) (interface{}, bool, error) {
+	if v, ok := src.(reflect.Value); ok {
+		if !v.CanInterface() {
+			return nil, false, nil
+		}
+		src = v.Interface()
 	}
+	if isNilPointer(src) {
+		return nil, false, nil
+	}
+	itemGetter, isItemGetter := src.(ItemGetter)
+	attrGetter, isAttrGetter := src.(AttrGetter)
+	switch {
+	case isItemGetter && (item || !isAttrGetter):
+		val, err := itemGetter.PyGetItem(name)
+		if err != nil {
+			return nil, true, missingError{err}
+		}
+		return val, true, nil
+	case isAttrGetter:
+		val, err := attrGetter.PyGetAttr(name)
+		if err != nil {
+			return nil, true, missingError{err}
+		}
+		return val, true, nil
+	}
+	return nil, false, nil
+}
+
+// fieldByName gets a struct field, map value, or slice or array element by name.
+func (fm *Formatter) fieldByName(name string, src interface{}) (interface{}, error) {
+	srcVal := valueOf(src)
 
 	switch srcVal.Kind() {
 	case reflect.Ptr:
 		if srcVal.IsNil() {
-			return nil, Error("attempted to dereference nil pointer {}", name)
+			return nil, missingError{Error("attempted to dereference nil pointer {}", name)}
+		}
+		return fm.fieldByName(name, reflect.Indirect(srcVal))
+	case reflect.Interface:
+		if srcVal.IsNil() {
+			return nil, missingError{Error("attempted to get {} from nil interface", name)}
 		}
-		return elementByName(name, reflect.Indirect(srcVal))
+		return fm.fieldByName(name, srcVal.Elem())
 	case reflect.Struct:
-		v := srcVal.FieldByName(name)
-		if v.IsValid() {
-			if v.CanInterface() {
-				return v.Interface(), nil
+		index, err := fm.fieldIndex(srcVal.Type(), name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := fieldByIndex(srcVal, index)
+		if err != nil {
+			if fm.NilSafe {
+				return nilPath{}, nil
 			}
-			return v, nil
+			return nil, err
+		}
+		if v.CanInterface() {
+			return v.Interface(), nil
 		}
-		return nil, Error("could not find field: {}", name)
+		if !srcVal.CanAddr() && srcVal.CanInterface() {
+			// Unexported fields can't be copied out of a struct, so look the field up in an
+			// addressable copy of the struct instead, so that an array in it can be sliced.
+			addr := reflect.New(srcVal.Type()).Elem()
+			addr.Set(srcVal)
+			v = addr.FieldByIndex(index)
+		}
+		return v, nil
 	case reflect.Map:
-		if !(reflect.ValueOf(name).Type().AssignableTo(srcVal.Type().Key())) {
-			return nil, Error("could not look up key {} from map {}", name, src)
+		keys, err := mapKeys(name, srcVal.Type().Key())
+		if err != nil {
+			return nil, missingError{Error("could not look up key {} from map {}: {}", name, src, err)}
+		}
+		var v reflect.Value
+		for _, key := range keys {
+			if v = srcVal.MapIndex(key); v.IsValid() {
+				break
+			}
 		}
-		v := srcVal.MapIndex(reflect.ValueOf(name))
 		if v.IsValid() {
 			if v.CanInterface() {
 				return v.Interface(), nil
 			}
 			return v, nil
 		}
-		return nil, Error("could not find key: {}", name)
-	case reflect.Array, reflect.Slice:
-		if parse, err := strconv.ParseUint(name, 10, 64); err == nil {
-			if parse < uint64(srcVal.Len()) {
-				v := srcVal.Index(int(parse))
-				if v.IsValid() {
-					if v.CanInterface() {
-						return v.Interface(), nil
+		return nil, missingError{Error("could not find key: {}", name)}
+	case reflect.Array, reflect.Slice, reflect.String:
+		if strings.IndexByte(name, ':') >= 0 {
+			return sliceByRange(name, srcVal)
+		}
+		return elementByIndex(name, srcVal)
+	default:
+		return nil, missingError{Error("attempted to get item by name from non-struct, non-map: {} {}", src, srcVal.Kind())}
+	}
+}
+
+// textUnmarshalerType is the type of the encoding.TextUnmarshaler interface.
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// mapKeys converts the text of a key to the key type of a map. Types implementing
+// encoding.TextUnmarshaler are converted with UnmarshalText, and other types are parsed according
+// to their kind. For interface key types, like the map[interface{}]interface{} YAML decoders
+// produce, returns each form the key could take, to be tried in order: the string, then an int, a
+// float64, or a bool if the text parses as one.
+func mapKeys(name string, keyType reflect.Type) ([]reflect.Value, error) {
+	if keyType.Kind() == reflect.Interface {
+		keys := []reflect.Value{reflect.ValueOf(name)}
+		if i, err := strconv.ParseInt(name, 10, 0); err == nil {
+			keys = append(keys, reflect.ValueOf(int(i)))
+		}
+		if f, err := strconv.ParseFloat(name, 64); err == nil {
+			keys = append(keys, reflect.ValueOf(f))
+		}
+		if b, err := strconv.ParseBool(name); err == nil {
+			keys = append(keys, reflect.ValueOf(b))
+		}
+		var assignable []reflect.Value
+		for _, key := range keys {
+			if key.Type().AssignableTo(keyType) {
+				assignable = append(assignable, key)
+			}
+		}
+		return assignable, nil
+	}
+
+	key := reflect.New(keyType)
+	if keyType.Kind() != reflect.Ptr && reflect.PtrTo(keyType).Implements(textUnmarshalerType) {
+		if err := key.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(name)); err != nil {
+			return nil, err
+		}
+		return []reflect.Value{key.Elem()}, nil
+	}
+	key = key.Elem()
+	switch keyType.Kind() {
+	case reflect.String:
+		key.SetString(name)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(name, 10, keyType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		key.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(name, 10, keyType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		key.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(name, keyType.Bits())
+		if err != nil {
+			return nil, err
+		}
+		key.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(name)
+		if err != nil {
+			return nil, err
+		}
+		key.SetBool(b)
+	default:
+		return nil, Error("unsupported key type {}", keyType)
+	}
+	return []reflect.Value{key}, nil
+}
+
+// elementByIndex gets an element of a slice or array, or a character of a string, by its index.
+// Like Python, negative indexes count back from the end.
+func elementByIndex(name string, v reflect.Value) (interface{}, error) {
+	parse, err := strconv.ParseInt(name, 10, 64)
+	if err != nil {
+		return nil, Error("could not parse index: {}", name)
+	}
+	var runes []rune
+	length := v.Len()
+	if v.Kind() == reflect.String {
+		runes = []rune(v.String())
+		length = len(runes)
+	}
+	i := parse
+	if i < 0 {
+		i += int64(length)
+	}
+	if i < 0 || i >= int64(length) {
+		return nil, missingError{Error("index out of bounds: {}", parse)}
+	}
+	if runes != nil {
+		return string(runes[i]), nil
+	}
+	elem := v.Index(int(i))
+	if elem.CanInterface() {
+		return elem.Interface(), nil
+	}
+	return elem, nil
+}
+
+// sliceByRange gets a sub-slice of a slice or array, or a substring of a string, from a range like
+// "1:3", ":8", or "-2:". Like Python, either bound may be left out or negative, and bounds past
+// either end are clamped to it.
+func sliceByRange(name string, v reflect.Value) (interface{}, error) {
+	lo, hi := split(name, ':')
+	if strings.IndexByte(hi, ':') >= 0 {
+		return nil, Error("slice steps are not supported: {}", name)
+	}
+	var runes []rune
+	length := v.Len()
+	if v.Kind() == reflect.String {
+		runes = []rune(v.String())
+		length = len(runes)
+	}
+	start, err := sliceBound(lo, 0, length)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := sliceBound(hi, length, length)
+	if err != nil {
+		return nil, err
+	}
+	if stop < start {
+		stop = start
+	}
+	if runes != nil {
+		return string(runes[start:stop]), nil
+	}
+	if v.Kind() == reflect.Array && !v.CanAddr() {
+		// Only addressable arrays can be sliced, so slice a copy.
+		if !v.CanInterface() {
+			return nil, Error("cannot slice unexported array that isn't addressable: {}", v.Type())
+		}
+		arr := reflect.New(v.Type()).Elem()
+		arr.Set(v)
+		v = arr
+	}
+	sub := v.Slice(start, stop)
+	if sub.CanInterface() {
+		return sub.Interface(), nil
+	}
+	return sub, nil
+}
+
+// sliceBound parses one bound of a slice range, returning def if it's empty.
+func sliceBound(bound string, def, length int) (int, error) {
+	if bound == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(bound)
+	if err != nil {
+		return 0, Error("could not parse slice bound: {}", bound)
+	}
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0, nil
+	}
+	if i > length {
+		return length, nil
+	}
+	return i, nil
+}
+
+// structField is a field of a struct, or of a struct embedded in it, that can be looked up by name.
+type structField struct {
+	index []int
+	// depth is how many embedded structs the field is promoted through.
+	depth int
+	// name is the field's name from its pyfmt tag, or its Go name if it has none.
+	name   string
+	goName string
+	// jsonName is the field's name from its json tag, if its pyfmt tag doesn't name it.
+	jsonName string
+}
+
+// structFieldsCache maps struct types to their []structField.
+var structFieldsCache sync.Map
+
+// structFields returns all the fields of a struct type that can be looked up by name, including
+// those promoted from embedded structs, sorted by depth. Fields tagged `pyfmt:"-"` are left out.
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	next := []embedded{{t, nil}}
+	seen := map[reflect.Type]bool{}
+	for depth := 0; len(next) > 0; depth++ {
+		current := next
+		next = nil
+		for _, e := range current {
+			// A struct embedded again deeper than it was already embedded has all of its fields
+			// hidden, but one embedded more than once at the same depth adds each of its fields
+			// once per copy, so that they're ambiguous.
+			if seen[e.t] {
+				continue
+			}
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				tag := parseFieldTag(f)
+				if tag.skip {
+					continue
+				}
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+				field := structField{index: index, depth: depth, name: tag.name, goName: f.Name}
+				if pyName, _ := split(f.Tag.Get(tagName), ','); pyName == "" {
+					jsonName, _ := split(f.Tag.Get("json"), ',')
+					if jsonName != "-" {
+						field.jsonName = jsonName
 					}
-					return v, nil
 				}
-				return nil, Error("could not get index: {}", name)
+				fields = append(fields, field)
+
+				ft := f.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if f.Anonymous && ft.Kind() == reflect.Struct && tag.name == f.Name {
+					next = append(next, embedded{ft, index})
+				}
 			}
-			return nil, Error("index out of bounds: {}", parse)
 		}
-		return nil, Error("could not parse index: {}", name)
-	default:
-		return nil, Error("attempted to get item by name from non-struct, non-map: {} {}", src, srcVal.Kind())
+		for _, e := range current {
+			seen[e.t] = true
+		}
 	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// fieldIndex finds the index of the field of a struct type with the name. Like Go, fields of
+// embedded structs are promoted, and a field hides fields with the same name embedded deeper,
+// but more than one field with the name at the same depth is an error. Names from tags are
+// preferred over Go names, and exact matches are preferred over case-insensitive ones.
+func (fm *Formatter) fieldIndex(t reflect.Type, name string) ([]int, error) {
+	fields := structFields(t)
+	index, err := fm.findField(fields, name, func(a, b string) bool { return a == b })
+	if index == nil && err == nil && fm.IgnoreFieldCase {
+		index, err = fm.findField(fields, name, strings.EqualFold)
+	}
+	if index == nil && err == nil {
+		return nil, missingError{Error("could not find field: {}", name)}
+	}
+	return index, err
+}
+
+// findField finds the shallowest field with the name, using match to compare names. Returns a nil
+// index if there isn't one.
+func (fm *Formatter) findField(fields []structField, name string, match func(a, b string) bool) ([]int, error) {
+	for i := 0; i < len(fields); {
+		var tagged, named []structField
+		for depth := fields[i].depth; i < len(fields) && fields[i].depth == depth; i++ {
+			f := fields[i]
+			if match(f.name, name) || (fm.JSONTags && f.jsonName != "" && match(f.jsonName, name)) {
+				tagged = append(tagged, f)
+			} else if match(f.goName, name) {
+				named = append(named, f)
+			}
+		}
+		matches := tagged
+		if len(matches) == 0 {
+			matches = named
+		}
+		if len(matches) == 1 {
+			return matches[0].index, nil
+		}
+		if len(matches) > 1 {
+			return nil, Error("ambiguous field name: {}", name)
+		}
+	}
+	return nil, nil
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but errors on nil embedded pointers instead of
+// panicking.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, missingError{Error("attempted to dereference nil embedded pointer {}", v.Type())}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// errorType is the type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// methodByName returns the exported method with the name, if the value has one that takes no
+// arguments, and returns either a single value, or a value and an error. Methods with pointer
+// receivers are found on values too, by calling them on a pointer to a copy of the value if the
+// value isn't addressable.
+func methodByName(name string, src interface{}) (reflect.Value, bool) {
+	v := valueOf(src)
+	if !v.IsValid() || !v.CanInterface() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return reflect.Value{}, false
+	}
+	method := v.MethodByName(name)
+	if !method.IsValid() && v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+		if v.CanAddr() {
+			v = v.Addr()
+		} else {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			v = ptr
+		}
+		method = v.MethodByName(name)
+	}
+	if !method.IsValid() {
+		return reflect.Value{}, false
+	}
+	t := method.Type()
+	if t.NumIn() != 0 || t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		return reflect.Value{}, false
+	}
+	return method, true
+}
+
+// callMethod calls a method found by methodByName, returning its error as a format error.
+func callMethod(name string, method reflect.Value) (interface{}, error) {
+	out := method.Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, Error("error calling method {}: {}", name, out[1].Interface())
+	}
+	return out[0].Interface(), nil
+}
+
+// valueOf returns the reflect.Value for an element, unwrapping elements that are already
+// reflect.Values (e.g., those returned by elementByName for unexported fields).
+func valueOf(src interface{}) reflect.Value {
+	if v, ok := src.(reflect.Value); ok {
+		return v
+	}
+	return reflect.ValueOf(src)
+}
+
+// visited tracks the pointers, maps, and slices currently being walked, so that walking a value
+// that contains itself can stop instead of recursing forever.
+type visited map[uintptr]bool
+
+// enter marks a value as being walked, returning false if it's already being walked further up
+// the stack.
+func (s visited) enter(v reflect.Value) bool {
+	if s[v.Pointer()] {
+		return false
+	}
+	s[v.Pointer()] = true
+	return true
+}
+
+// leave marks a value as no longer being walked.
+func (s visited) leave(v reflect.Value) {
+	delete(s, v.Pointer())
 }
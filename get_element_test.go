@@ -1,7 +1,9 @@
 package pyfmt
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -127,6 +129,504 @@ func TestGetElementErrors(t *testing.T) {
 	}
 }
 
+type person struct {
+	First, Last string
+	Nick        string
+}
+
+func (p person) FullName() string { return p.First + " " + p.Last }
+
+func (p *person) Initials() string { return p.First[:1] + p.Last[:1] }
+
+func (p person) Nickname() (string, error) {
+	if p.Nick == "" {
+		return "", errors.New("no nickname")
+	}
+	return p.Nick, nil
+}
+
+func (p person) Greet(greeting string) string { return greeting + " " + p.First }
+
+func (p person) Pair() (string, string) { return p.First, p.Last }
+
+func (p person) Update() {}
+
+type people []person
+
+func (p people) First() person { return p[0] }
+
+func TestGetElementMethods(t *testing.T) {
+	ann := person{First: "Ann", Last: "Lee", Nick: "al"}
+
+	tests := []struct {
+		elems     []interface{}
+		lookupStr string
+		want      interface{}
+	}{
+		{[]interface{}{ann}, "FullName", "Ann Lee"},
+		{[]interface{}{&ann}, "FullName", "Ann Lee"},
+		{[]interface{}{ann}, "Initials", "AL"},
+		{[]interface{}{&ann}, "Initials", "AL"},
+		{[]interface{}{ann}, "Nickname", "al"},
+		{[]interface{}{ann}, "First", "Ann"},
+		{[]interface{}{map[string]person{"a": ann}}, "a.FullName", "Ann Lee"},
+		{[]interface{}{map[string]*person{"a": &ann}}, "a.Initials", "AL"},
+		{[]interface{}{people{ann}}, "First.FullName", "Ann Lee"},
+		{[]interface{}{people{ann}}, "0[0].Initials", "AL"},
+		{[]interface{}{[]person{ann}}, "0[0].FullName", "Ann Lee"},
+	}
+
+	for _, test := range tests {
+		got, err := getElement(test.lookupStr, 0, test.elems...)
+		if err != nil {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) Errored: {1}", test, err))
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) = {1} Want: {want}", test, got))
+		}
+	}
+}
+
+func TestGetElementMethodsError(t *testing.T) {
+	noMethods := &Formatter{DisableMethods: true}
+
+	tests := []struct {
+		fm        *Formatter
+		elems     []interface{}
+		lookupStr string
+	}{
+		{std, []interface{}{person{First: "Ann"}}, "Nickname"},
+		{std, []interface{}{person{First: "Ann"}}, "Greet"},
+		{std, []interface{}{person{First: "Ann"}}, "Pair"},
+		{std, []interface{}{person{First: "Ann"}}, "Update"},
+		{std, []interface{}{person{First: "Ann"}}, "fullName"},
+		{std, []interface{}{(*person)(nil)}, "FullName"},
+		{std, []interface{}{struct{ p person }{}}, "p.FullName"},
+		{noMethods, []interface{}{person{First: "Ann", Last: "Lee"}}, "FullName"},
+		{noMethods, []interface{}{people{{}}}, "First"},
+	}
+
+	for _, test := range tests {
+		_, err := test.fm.getElement(test.lookupStr, 0, test.elems...)
+		if err == nil {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) Did not error!", test))
+		}
+	}
+
+	_, err := getElement("Nickname", 0, person{})
+	if err == nil || err.Error() != "error calling method Nickname: no nickname" {
+		t.Error(Must("getElement(Nickname) error = {}, Want: error calling method Nickname: no nickname", err))
+	}
+}
+
+type account struct {
+	UserID  int    `pyfmt:"user_id"`
+	Email   string `json:"email,omitempty"`
+	Secret  string `pyfmt:"-"`
+	Display string `pyfmt:",omitempty" json:"display_name"`
+	Note    string `json:"-"`
+}
+
+type base struct {
+	ID      int
+	Created string
+}
+
+type audit struct {
+	ID      string
+	Changed string
+}
+
+type record struct {
+	base
+	*audit
+	Name  string
+	Value interface{}
+	extra interface{}
+}
+
+// created and changed both embed base, so the fields of base are ambiguous in history.
+type created struct {
+	base
+}
+
+type changed struct {
+	base
+}
+
+type history struct {
+	created
+	changed
+}
+
+func TestGetElementFields(t *testing.T) {
+	acct := account{UserID: 7, Email: "a@b.c", Secret: "s", Display: "Ann", Note: "n"}
+	rec := record{base: base{ID: 1, Created: "mon"}, audit: &audit{ID: "a1", Changed: "tue"}, Name: "r",
+		Value: acct, extra: base{ID: 2}}
+	jsonTags := &Formatter{JSONTags: true}
+	ignoreCase := &Formatter{IgnoreFieldCase: true, JSONTags: true}
+
+	tests := []struct {
+		fm        *Formatter
+		elems     []interface{}
+		lookupStr string
+		want      string
+	}{
+		{std, []interface{}{acct}, "user_id", "7"},
+		{std, []interface{}{acct}, "UserID", "7"},
+		{std, []interface{}{acct}, "Email", "a@b.c"},
+		{std, []interface{}{acct}, "Display", "Ann"},
+		{jsonTags, []interface{}{acct}, "email", "a@b.c"},
+		{jsonTags, []interface{}{acct}, "display_name", "Ann"},
+		{jsonTags, []interface{}{acct}, "Note", "n"},
+		{ignoreCase, []interface{}{acct}, "USER_ID", "7"},
+		{ignoreCase, []interface{}{acct}, "userid", "7"},
+		{ignoreCase, []interface{}{acct}, "EMAIL", "a@b.c"},
+		{std, []interface{}{rec}, "Created", "mon"},
+		{std, []interface{}{rec}, "Changed", "tue"},
+		{std, []interface{}{rec}, "base.ID", "1"},
+		{std, []interface{}{rec}, "audit.ID", "a1"},
+		{std, []interface{}{&rec}, "Name", "r"},
+		{std, []interface{}{rec}, "Value.user_id", "7"},
+		{std, []interface{}{rec}, "extra.ID", "2"},
+		{std, []interface{}{history{changed: changed{base{ID: 3}}}}, "changed.ID", "3"},
+		{std, []interface{}{struct {
+			base
+			created
+		}{base{ID: 4}, created{}}}, "ID", "4"},
+	}
+
+	for _, test := range tests {
+		got, err := test.fm.getElement(test.lookupStr, 0, test.elems...)
+		if err != nil {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) Errored: {1}", test, err))
+		}
+		if Must("{}", got) != test.want {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) = {1} Want: {want}", test, got))
+		}
+	}
+}
+
+func TestGetElementFieldsError(t *testing.T) {
+	acct := account{UserID: 7, Secret: "s"}
+	ignoreCase := &Formatter{IgnoreFieldCase: true}
+
+	tests := []struct {
+		fm        *Formatter
+		elems     []interface{}
+		lookupStr string
+		wantErr   string
+	}{
+		{std, []interface{}{acct}, "Secret", "could not find field: Secret"},
+		{std, []interface{}{acct}, "email", "could not find field: email"},
+		{std, []interface{}{acct}, "USER_ID", "could not find field: USER_ID"},
+		{std, []interface{}{record{audit: &audit{}}}, "ID", "ambiguous field name: ID"},
+		{std, []interface{}{history{}}, "ID", "ambiguous field name: ID"},
+		{std, []interface{}{history{}}, "Created", "ambiguous field name: Created"},
+		{std, []interface{}{record{}}, "Changed", "attempted to dereference nil embedded pointer *pyfmt.audit"},
+		{std, []interface{}{record{}}, "extra.ID", "attempted to get ID from nil interface"},
+		{ignoreCase, []interface{}{struct{ ID, Id int }{}}, "id", "ambiguous field name: id"},
+	}
+
+	for _, test := range tests {
+		_, err := test.fm.getElement(test.lookupStr, 0, test.elems...)
+		if err == nil || err.Error() != test.wantErr {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) error = {1}, Want: {wantErr}", test, err))
+		}
+	}
+
+	// Exact matches win over case-insensitive ones.
+	got, err := ignoreCase.getElement("Id", 0, struct{ ID, Id int }{1, 2})
+	if err != nil || got != 2 {
+		t.Error(Must("getElement(Id) = {}, {}, Want: 2", got, err))
+	}
+}
+
+func TestGetElementIndexes(t *testing.T) {
+	items := []int{1, 2, 3, 4}
+	vars := map[string]interface{}{"items": items, "arr": [3]string{"a", "b", "c"}, "name": "héllo"}
+
+	tests := []struct {
+		lookupStr string
+		want      interface{}
+	}{
+		{"items[-1]", 4},
+		{"items[-4]", 1},
+		{"items.-2", 3},
+		{"arr[-1]", "c"},
+		{"name[1]", "é"},
+		{"name[-1]", "o"},
+		{"items[1:3]", []int{2, 3}},
+		{"items[:2]", []int{1, 2}},
+		{"items[2:]", []int{3, 4}},
+		{"items[:]", []int{1, 2, 3, 4}},
+		{"items[-2:]", []int{3, 4}},
+		{"items[:-3]", []int{1}},
+		{"items[-10:10]", []int{1, 2, 3, 4}},
+		{"items[3:1]", []int{}},
+		{"arr[1:]", []string{"b", "c"}},
+		{"name[:2]", "hé"},
+		{"name[-3:]", "llo"},
+		{"name[10:]", ""},
+		{"items[1:3][-1]", 3},
+	}
+
+	for _, test := range tests {
+		got, err := getElement(test.lookupStr, 0, vars)
+		if err != nil {
+			t.Error(Must("getElement({lookupStr}) Errored: {1}", test, err))
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Error(Must("getElement({lookupStr}) = {1} ({1:t}) Want: {want} ({want:t})", test, got))
+		}
+	}
+}
+
+type window struct {
+	days [3]int
+}
+
+type schedule struct {
+	in    window
+	weeks map[string][2]int
+}
+
+func TestFmtUnexportedArraySlice(t *testing.T) {
+	sched := schedule{in: window{[3]int{1, 2, 3}}, weeks: map[string][2]int{"a": {4, 5}}}
+
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{0.in.days[1]}", sched, "2"},
+		{"{0.in.days[1:]}", sched, "[2 3]"},
+		{"{0.in.days[:-1]}", &sched, "[1 2]"},
+		{"{0.days[1:]}", window{[3]int{1, 2, 3}}, "[2 3]"},
+		{"{0.in.days[1:]:R}", sched, "[2, 3]"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}) = {1}, Want: {want}", test, got))
+		}
+	}
+
+	// Arrays in unexported maps can't be copied, so can't be sliced.
+	want := "cannot slice unexported array that isn't addressable: [2]int"
+	if _, err := Fmt("{0.weeks[a][1:]}", sched); err == nil || err.Error() != want {
+		t.Error(Must("Fmt({{0.weeks[a][1:]}}) error = {}, Want: {}", err, want))
+	}
+}
+
+func TestGetElementIndexesError(t *testing.T) {
+	vars := map[string]interface{}{"items": []int{1, 2}, "name": "ab"}
+
+	tests := []struct {
+		lookupStr string
+		wantErr   string
+	}{
+		{"items[2]", "index out of bounds: 2"},
+		{"items[-3]", "index out of bounds: -3"},
+		{"name[-3]", "index out of bounds: -3"},
+		{"items[x]", "could not parse index: x"},
+		{"items[x:]", "could not parse slice bound: x"},
+		{"items[:1.5]", "could not parse slice bound: 1.5"},
+		{"items[0:1:2]", "slice steps are not supported: 0:1:2"},
+	}
+
+	for _, test := range tests {
+		_, err := getElement(test.lookupStr, 0, vars)
+		if err == nil || err.Error() != test.wantErr {
+			t.Error(Must("getElement({lookupStr}) error = {1}, Want: {wantErr}", test, err))
+		}
+	}
+}
+
+type color string
+
+// level is a map key type that's unmarshaled from its name.
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+func TestGetElementMapKeys(t *testing.T) {
+	yaml := map[interface{}]interface{}{"1": "string one", 2: "int two", 2.5: "float", true: "yes", "x": "ex"}
+
+	tests := []struct {
+		elems     []interface{}
+		lookupStr string
+		want      interface{}
+	}{
+		{[]interface{}{map[int]string{8080: "http"}}, "0[8080]", "http"},
+		{[]interface{}{map[string]map[int]string{"ports": {8080: "http"}}}, "ports[8080]", "http"},
+		{[]interface{}{map[int8]string{-5: "neg"}}, "0[-5]", "neg"},
+		{[]interface{}{map[uint16]string{443: "https"}}, "0[443]", "https"},
+		{[]interface{}{map[float64]int{1.5: 3}}, "0[1.5]", 3},
+		{[]interface{}{map[float32]int{0.25: 4}}, "0[0.25]", 4},
+		{[]interface{}{map[bool]string{true: "on"}}, "0[true]", "on"},
+		{[]interface{}{map[color]int{"red": 1}}, "red", 1},
+		{[]interface{}{map[level]string{2: "loud"}}, "high", "loud"},
+		{[]interface{}{yaml}, "0[1]", "string one"},
+		{[]interface{}{yaml}, "[2]", "int two"},
+		{[]interface{}{yaml}, "[2.5]", "float"},
+		{[]interface{}{yaml}, "[true]", "yes"},
+		{[]interface{}{yaml}, "x", "ex"},
+	}
+
+	for _, test := range tests {
+		got, err := getElement(test.lookupStr, 0, test.elems...)
+		if err != nil {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) Errored: {1}", test, err))
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) = {1} Want: {want}", test, got))
+		}
+	}
+}
+
+func TestGetElementMapKeysError(t *testing.T) {
+	tests := []struct {
+		elems     []interface{}
+		lookupStr string
+	}{
+		{[]interface{}{map[int]string{1: "a"}}, "0[x]"},
+		{[]interface{}{map[int]string{1: "a"}}, "0[2]"},
+		{[]interface{}{map[int8]string{1: "a"}}, "0[300]"},
+		{[]interface{}{map[uint]string{1: "a"}}, "0[-1]"},
+		{[]interface{}{map[bool]string{true: "a"}}, "0[maybe]"},
+		{[]interface{}{map[level]string{1: "a"}}, "0[medium]"},
+		{[]interface{}{map[[2]int]string{{1, 2}: "a"}}, "0[1]"},
+		{[]interface{}{map[interface{}]string{1: "a"}}, "0[2]"},
+	}
+
+	for _, test := range tests {
+		_, err := getElement(test.lookupStr, 0, test.elems...)
+		if err == nil {
+			t.Error(Must("getElement({lookupStr}, 0, {elems}) Did not error!", test))
+		}
+	}
+}
+
+func TestGetElementQuotedKeys(t *testing.T) {
+	labels := map[string]map[string]string{"labels": {
+		"app.kubernetes.io/name": "web",
+		"a[0]":                   "bracket",
+		`"quoted"`:               "quotes",
+		"a:b":                    "colon",
+	}}
+
+	tests := []struct {
+		lookupStr string
+		want      interface{}
+	}{
+		{`labels["app.kubernetes.io/name"]`, "web"},
+		{`labels['app.kubernetes.io/name']`, "web"},
+		{`labels["a[0]"]`, "bracket"},
+		{`labels['"quoted"']`, "quotes"},
+		{`labels["\"quoted\""]`, "quotes"},
+		{`labels["a:b"]`, "colon"},
+	}
+
+	for _, test := range tests {
+		got, err := getElement(test.lookupStr, 0, labels)
+		if err != nil {
+			t.Error(Must("getElement({lookupStr}) Errored: {1}", test, err))
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Error(Must("getElement({lookupStr}) = {1} Want: {want}", test, got))
+		}
+	}
+}
+
+// config is a dynamic object that looks up items itself.
+type config map[string]interface{}
+
+func (c config) PyGetItem(key string) (interface{}, error) {
+	if val, ok := c[key]; ok {
+		return val, nil
+	}
+	return nil, errors.New("no config key " + key)
+}
+
+// lazyRecord is a lazily-loaded object with both items and attributes.
+type lazyRecord struct {
+	fields map[string]string
+}
+
+func (r *lazyRecord) PyGetItem(key string) (interface{}, error) {
+	return "item " + r.fields[key], nil
+}
+
+func (r *lazyRecord) PyGetAttr(name string) (interface{}, error) {
+	return "attr " + r.fields[name], nil
+}
+
+// attrs only has attributes.
+type attrs struct{}
+
+func (attrs) PyGetAttr(name string) (interface{}, error) {
+	return strings.ToUpper(name), nil
+}
+
+func TestGetElementGetters(t *testing.T) {
+	rec := &lazyRecord{map[string]string{"a": "1", "b": "2"}}
+	cfg := config{"db": config{"host": "localhost"}, "rec": rec, "attrs": attrs{}, "list": []int{1, 2}}
+
+	tests := []struct {
+		lookupStr string
+		want      interface{}
+	}{
+		{"db", config{"host": "localhost"}},
+		{"db.host", "localhost"},
+		{"db[host]", "localhost"},
+		{"rec[a]", "item 1"},
+		{"rec.a", "attr 1"},
+		{"rec[a:b]", "item "},
+		{"attrs.x", "X"},
+		{"attrs[y]", "Y"},
+		{"list[-1]", 2},
+	}
+
+	for _, test := range tests {
+		got, err := getElement(test.lookupStr, 0, cfg)
+		if err != nil {
+			t.Error(Must("getElement({lookupStr}) Errored: {1}", test, err))
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Error(Must("getElement({lookupStr}) = {1} Want: {want}", test, got))
+		}
+	}
+
+	// The first part of a name is a key.
+	if got, _ := getElement("b", 0, rec); got != "item 2" {
+		t.Error(Must("getElement(b) = {} Want: item 2", got))
+	}
+	if got := Must("{0.a}|{0[b]:>7}", rec); got != "attr 1| item 2" {
+		t.Error(Must("Must() = {} Want: attr 1| item 2", got))
+	}
+	if _, err := getElement("db.port", 0, cfg); err == nil || err.Error() != "no config key port" {
+		t.Error(Must("getElement(db.port) error = {}, Want: no config key port", err))
+	}
+	if _, err := getElement("a", 0, (*lazyRecord)(nil)); err == nil {
+		t.Error("getElement(a) on a nil getter did not error when expected!")
+	}
+}
+
 func TestSplitName(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -144,6 +644,16 @@ func TestSplitName(t *testing.T) {
 		{"[3].4[5]", "3", "4[5]"},
 		{"4[5]", "4", "[5]"},
 		{"[5]", "5", ""},
+		{"[3][4][5]", "3", "[4][5]"},
+		{"[1.5].a", "1.5", "a"},
+		{"[1:3][-1]", "1:3", "[-1]"},
+		{`["app.kubernetes.io/name"]`, "app.kubernetes.io/name", ""},
+		{`['a[0]'].b`, "a[0]", "b"},
+		{`["a]b"]["c"]`, "a]b", `["c"]`},
+		{`["say \"hi\""]`, `say "hi"`, ""},
+		{`['it\'s']`, "it's", ""},
+		{`['back\\slash']`, `back\slash`, ""},
+		{`[""]`, "", ""},
 	}
 
 	for _, test := range tests {
@@ -169,6 +679,11 @@ func TestSplitNameError(t *testing.T) {
 		{"[[["},
 		{"]"},
 		{"]]]"},
+		{`["abc]`},
+		{`["abc"`},
+		{`["abc"x]`},
+		{`["abc"]x`},
+		{`['abc\']`},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,55 @@
+package pyfmt
+
+// LazyValue is an argument that's only computed if the format string uses it, for values that are
+// expensive to compute, like stack traces or serialized requests. It's computed at most once per
+// format, however many times the format string uses it. LazyValues may be passed as arguments, or
+// held in the maps, structs, and slices passed as arguments.
+type LazyValue struct {
+	fn func() (interface{}, error)
+}
+
+// Lazy returns an argument whose value is computed by calling fn, only if the format string uses
+// it.
+func Lazy(fn func() interface{}) *LazyValue {
+	return &LazyValue{func() (interface{}, error) { return fn(), nil }}
+}
+
+// LazyErr is like Lazy, but an error returned by fn is returned as a format error.
+func LazyErr(fn func() (interface{}, error)) *LazyValue {
+	return &LazyValue{fn}
+}
+
+// lazyCache holds the values of the LazyValues computed during a format, so each is computed at
+// most once. A nil lazyCache computes values without caching them.
+type lazyCache struct {
+	values map[*LazyValue]interface{}
+}
+
+// resolve returns the value of a LazyValue, computing it if it hasn't been yet, or returns any
+// other value unchanged.
+func (c *lazyCache) resolve(val interface{}) (interface{}, error) {
+	l, ok := val.(*LazyValue)
+	if !ok || l == nil {
+		return val, nil
+	}
+	if c != nil {
+		if v, ok := c.values[l]; ok {
+			return v, nil
+		}
+	}
+	v, err := l.fn()
+	if err != nil {
+		return nil, Error("error computing lazy value: {}", err)
+	}
+	// A LazyValue may return another LazyValue, which is computed in turn.
+	if v, err = c.resolve(v); err != nil {
+		return nil, err
+	}
+	if c != nil {
+		if c.values == nil {
+			c.values = map[*LazyValue]interface{}{}
+		}
+		c.values[l] = v
+	}
+	return v, nil
+}
@@ -0,0 +1,88 @@
+package pyfmt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	trace := Lazy(func() interface{} {
+		calls++
+		return "trace"
+	})
+	nested := Lazy(func() interface{} { return Lazy(func() interface{} { return 42 }) })
+	vars := map[string]interface{}{"trace": trace, "n": 3, "obj": Lazy(func() interface{} {
+		return map[string]int{"x": 1}
+	})}
+
+	tests := []struct {
+		fmtStr    string
+		params    []interface{}
+		want      string
+		wantCalls int
+	}{
+		{"{n}", []interface{}{vars}, "3", 0},
+		{"{trace}", []interface{}{vars}, "trace", 1},
+		{"{trace:>6}|{trace:<6}|{trace}", []interface{}{vars}, " trace|trace |trace", 1},
+		{"{obj[x]}", []interface{}{vars}, "1", 0},
+		{"{} {}", []interface{}{trace, trace}, "trace trace", 1},
+		{"{1}", []interface{}{trace, 2}, "2", 0},
+		{"{:d}", []interface{}{nested}, "42", 0},
+	}
+
+	for _, test := range tests {
+		calls = 0
+		got, err := Fmt(test.fmtStr, test.params...)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {params}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {params}) = {1}, Want: {want}", test, got))
+		}
+		if calls != test.wantCalls {
+			t.Error(Must("Fmt({fmtStr}, {params}) computed the lazy value {1} times, Want: {wantCalls}", test, calls))
+		}
+	}
+
+	// Each format computes the value again.
+	calls = 0
+	Must("{trace}", vars)
+	Must("{trace}", vars)
+	if calls != 2 {
+		t.Error(Must("Two formats computed the lazy value {} times, Want: 2", calls))
+	}
+
+	calls = 0
+	if got := PercentMust("%(trace)s", vars); got != "trace" || calls != 1 {
+		t.Error(Must("PercentMust() = {}, computed the lazy value {} times, Want: trace, 1", got, calls))
+	}
+	if got := PercentMust("%s-%s", trace, trace); got != "trace-trace" || calls != 2 {
+		t.Error(Must("PercentMust() = {}, computed the lazy value {} times, Want: trace-trace, 2", got, calls))
+	}
+	if got, _ := Substitute("$trace ${trace}", vars); got != "trace trace" || calls != 3 {
+		t.Error(Must("Substitute() = {}, computed the lazy value {} times, Want: trace trace, 3", got, calls))
+	}
+	if got := MustArgs("{trace}", NewScope(vars)); got != "trace" || calls != 4 {
+		t.Error(Must("MustArgs() = {}, computed the lazy value {} times, Want: trace, 4", got, calls))
+	}
+}
+
+func TestLazyErr(t *testing.T) {
+	ok := LazyErr(func() (interface{}, error) { return "ok", nil })
+	bad := LazyErr(func() (interface{}, error) { return nil, errors.New("no request") })
+
+	if got := Must("{}", ok); got != "ok" {
+		t.Error(Must("Must({{}}, ok) = {}, Want: ok", got))
+	}
+	if got := Must("{0}", ok, bad); got != "ok" {
+		t.Error(Must("Must({{0}}, ok, bad) = {}, Want: ok", got))
+	}
+	_, err := Fmt("{req}", map[string]interface{}{"req": bad})
+	if err == nil || err.Error() != "error computing lazy value: no request" {
+		t.Error(Must("Fmt({{req}}) error = {}, Want: error computing lazy value: no request", err))
+	}
+	if _, err := PercentFmt("%s", bad); err == nil {
+		t.Error("PercentFmt() of a failing lazy value did not error when expected!")
+	}
+}
@@ -14,12 +14,15 @@ def main():
     doc = []
 
     for line in open("README.md"):
-        if line.startswith("[![Build"):
+        if line.startswith("[![Build") or line.startswith("![Build"):
             continue
-        if line == "pyfmt":
+        if line.strip() == "# pyfmt":
+            continue
+        # Skip the blank lines left before the package comment starts.
+        if not doc and not line.strip():
             continue
         # Fix up the header to be in pyfmt format
-        if line.startswith("pyfmt implements"):
+        if not doc and line.startswith("pyfmt implements"):
             line = "Package " + line
         if line.startswith("```"):
             continue
@@ -29,7 +32,7 @@ def main():
 
     with open("doc.go", 'w') as outp:
         outp.write("// Copyright 2018 Stephen Longfield, Jr.\n")
-        outp.write("// Autogenerated by makedoc.py DO NOT EDIT \n\n")
+        outp.write("// Autogenerated by makedoc.py DO NOT EDIT\n\n")
         outp.write("/*\n")
         for line in doc:
             outp.write(line)
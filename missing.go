@@ -0,0 +1,39 @@
+package pyfmt
+
+// MissingKeyPolicy says what a Formatter prints in place of a field name that can't be found,
+// e.g. a missing map key or struct field, or an index out of range.
+type MissingKeyPolicy int
+
+const (
+	// MissingKeyError returns an error, and is the default.
+	MissingKeyError MissingKeyPolicy = iota
+	// MissingKeyEmpty prints nothing.
+	MissingKeyEmpty
+	// MissingKeyMarker prints a marker naming the field, e.g. "<missing:name>".
+	MissingKeyMarker
+	// MissingKeyKeep leaves the replacement field in the output as is, e.g. "{name:>10}".
+	MissingKeyKeep
+)
+
+// missingError is an error looking up a field name because it can't be found, e.g. a missing map
+// key or struct field, or an index out of range, which may be replaced by a default or a
+// MissingKeyPolicy. Other errors, like a malformed field name or an error returned by a method or
+// a LazyErr, are always returned.
+type missingError struct {
+	error
+}
+
+// writeMissing writes the output for a field name that can't be found, following the Formatter's
+// MissingKeyPolicy. field is the whole replacement field, between the delimiters.
+func (f *ff) writeMissing(name, field, left, right string) {
+	switch f.cfg.MissingKey {
+	case MissingKeyMarker:
+		f.buf.WriteString("<missing:")
+		f.buf.WriteString(name)
+		f.buf.WriteString(">")
+	case MissingKeyKeep:
+		f.buf.WriteString(left)
+		f.buf.WriteString(field)
+		f.buf.WriteString(right)
+	}
+}
@@ -0,0 +1,103 @@
+package pyfmt
+
+import (
+	"errors"
+	"testing"
+)
+
+type brokenUser struct {
+	Name string
+}
+
+func (brokenUser) Email() (string, error) {
+	return "", errors.New("no email")
+}
+
+func TestMissingKey(t *testing.T) {
+	empty := &Formatter{MissingKey: MissingKeyEmpty}
+	marker := &Formatter{MissingKey: MissingKeyMarker}
+	keep := &Formatter{MissingKey: MissingKeyKeep}
+	keepAngles := &Formatter{MissingKey: MissingKeyKeep, LeftDelim: "<<", RightDelim: ">>"}
+	var nobody *brokenUser
+	vars := map[string]interface{}{"name": "ann", "tags": []string{"a"}, "user": brokenUser{"bob"},
+		"nobody": nobody, "cfg": config{"db": "pg"}, "ids": map[int]string{1: "a"}, "x|y": 1}
+
+	tests := []struct {
+		formatter *Formatter
+		fmtStr    string
+		want      string
+	}{
+		{std, "{name|anonymous}", "ann"},
+		{std, "{nickname|anonymous}", "anonymous"},
+		{std, "{nickname|anonymous:>10}", " anonymous"},
+		{std, "{nickname|}", ""},
+		{std, "[{nickname|a|b}]", "[a|b]"},
+		{std, "{tags[5]|none}", "none"},
+		{std, "{tags[0]|none}", "a"},
+		{std, "{user.Nick|-}", "-"},
+		{std, "{1|none}", "none"},
+		{std, "{x|y}", "1"},
+		{std, "{x|z}", "z"},
+		{std, "{tags[5]|[none]}", "[none]"},
+		{std, "{0[x|y]}", "1"},
+		{std, "{[x|y]|none}", "1"},
+		{std, "{nobody.Name|none}", "none"},
+		{std, "{cfg[host]|none}", "none"},
+		{std, "{ids[2]|none} {ids[x]|none}", "none none"},
+		{std, `{0["a|b"]|none}`, "none"},
+		{empty, "<{nickname}> <{name}>", "<> <ann>"},
+		{empty, "<{nickname:>10}>", "<>"},
+		{empty, "<{nickname|x}>", "<x>"},
+		{marker, "hi {nickname}", "hi <missing:nickname>"},
+		{marker, "{tags[1]}", "<missing:tags[1]>"},
+		{keep, "hi {nickname:>10} {name}", "hi {nickname:>10} ann"},
+		{keepAngles, "<<nickname>> <<name>>", "<<nickname>> ann"},
+	}
+
+	for _, test := range tests {
+		got, err := test.formatter.Fmt(test.fmtStr, vars)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}) = {1}, Want: {want}", test, got))
+		}
+	}
+
+	if got := keep.MustArgs("{HOME} {PATH}", EnvArgs([]string{"HOME=/root"})); got != "/root {PATH}" {
+		t.Error(Must("MustArgs() = {}, Want: /root {{PATH}}", got))
+	}
+}
+
+func TestMissingKeyError(t *testing.T) {
+	marker := &Formatter{MissingKey: MissingKeyMarker}
+	empty := &Formatter{MissingKey: MissingKeyEmpty}
+	bad := LazyErr(func() (interface{}, error) { return nil, errors.New("failed") })
+	vars := map[string]interface{}{"user": brokenUser{"bob"}, "lazy": bad, "tags": []string{"a"},
+		"history": history{}}
+
+	tests := []struct {
+		formatter *Formatter
+		fmtStr    string
+		wantErr   string
+	}{
+		{std, "{nickname}", "could not find key: nickname"},
+		{std, "{user.Email|none}", "error calling method Email: no email"},
+		{marker, "{user.Email}", "error calling method Email: no email"},
+		{marker, "{lazy|none}", "error computing lazy value: failed"},
+		{marker, "{0}{}", "cannot switch from manual field specification to automatic field numbering"},
+		// Malformed field names are always errors.
+		{empty, "<{a[}>", "unmatched [ in ["},
+		{empty, "{a]|x}", "unmatched ] in a]"},
+		{empty, "{tags[x]|none}", "could not parse index: x"},
+		{empty, "{tags[1:2:3]}", "slice steps are not supported: 1:2:3"},
+		{marker, "{history.ID|none}", "ambiguous field name: ID"},
+	}
+
+	for _, test := range tests {
+		_, err := test.formatter.Fmt(test.fmtStr, vars)
+		if err == nil || err.Error() != test.wantErr {
+			t.Error(Must("Fmt({fmtStr}) error = {1}, Want: {wantErr}", test, err))
+		}
+	}
+}
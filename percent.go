@@ -0,0 +1,456 @@
+package pyfmt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// percentSpec is a single conversion specifier in a printf-style format string, e.g. "%-10s" or
+// "%(name)05.2f".
+type percentSpec struct {
+	key    string
+	hasKey bool
+	left   bool
+	zero   bool
+	plus   bool
+	space  bool
+	alt    bool
+	// width and precision are -1 if not given.
+	width     int
+	precision int
+	conv      byte
+}
+
+// PercentFmt is the equivalent of Python's printf-style "%" operator, e.g. "%s: %5.2f" % (a, b).
+// Takes a list of values to substitute, or a single struct or map for the "%(name)s" form.
+func PercentFmt(format string, a ...interface{}) (string, error) {
+	return std.PercentFmt(format, a...)
+}
+
+// PercentMust is like PercentFmt, but panics on error.
+func PercentMust(format string, a ...interface{}) string {
+	return std.PercentMust(format, a...)
+}
+
+// PercentFmt is like the package-level PercentFmt, but uses the Formatter's options.
+func (fm *Formatter) PercentFmt(format string, a ...interface{}) (string, error) {
+	f := newFormater(fm)
+	defer f.free()
+	f.args = a
+	err := f.doPercentFormat(format)
+	if err != nil {
+		return "", err
+	}
+	s := string(f.buf.contents)
+	return s, nil
+}
+
+// PercentMust is like Formatter.PercentFmt, but panics on error.
+func (fm *Formatter) PercentMust(format string, a ...interface{}) string {
+	s, err := fm.PercentFmt(format, a...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// doPercentFormat parses a printf-style format string, and formats each conversion specifier.
+// Stores the output in ff's buf.
+func (f *ff) doPercentFormat(format string) error {
+	usedKeys := false
+	end := len(format)
+	for i := 0; i < end; {
+		cachei := i
+		for i < end && format[i] != '%' {
+			i++
+		}
+		if i > cachei {
+			f.buf.WriteString(format[cachei:i])
+		}
+		if i >= end {
+			break
+		}
+		spec, next, err := parsePercentSpec(format, i)
+		if err != nil {
+			return err
+		}
+		i = next
+		if spec.conv == '%' {
+			f.buf.WriteString("%")
+			continue
+		}
+		if spec.hasKey {
+			usedKeys = true
+		}
+		if err := f.percentStar(&spec.width, &spec.left, spec.hasKey); err != nil {
+			return err
+		}
+		if err := f.percentStar(&spec.precision, nil, spec.hasKey); err != nil {
+			return err
+		}
+		var val interface{}
+		if spec.hasKey {
+			if len(f.args) != 1 {
+				return errors.New("format requires a mapping")
+			}
+			val, err = f.cfg.lookupKey(spec.key, f.args, &f.lazy)
+		} else if usedKeys {
+			// Python treats a single mapping as the only argument, which a mapping key has used.
+			return errors.New("not enough arguments for format string")
+		} else {
+			val, err = f.nextPercentArg()
+		}
+		if err != nil {
+			return err
+		}
+		if err := f.writePercent(&spec, val); err != nil {
+			return err
+		}
+	}
+	if !usedKeys && f.listPos < len(f.args) {
+		return errors.New("not all arguments converted during string formatting")
+	}
+	return nil
+}
+
+// percentStarValue is stored in a width or precision given as '*', to be replaced with the next
+// argument.
+const percentStarValue = -2
+
+// percentStar replaces a '*' width or precision with the value of the next argument. A negative
+// width from an argument left-justifies the value, like in Python.
+func (f *ff) percentStar(n *int, left *bool, hasKey bool) error {
+	if *n != percentStarValue {
+		return nil
+	}
+	if hasKey {
+		return errors.New("* wants int, but the format uses a mapping")
+	}
+	arg, err := f.nextPercentArg()
+	if err != nil {
+		return err
+	}
+	val, ok := intOf(arg)
+	if !ok {
+		return errors.New("* wants int")
+	}
+	if val < 0 {
+		if left == nil {
+			val = -1
+		} else {
+			*left = true
+			val = -val
+		}
+	}
+	*n = int(val)
+	return nil
+}
+
+func (f *ff) nextPercentArg() (interface{}, error) {
+	if f.listPos >= len(f.args) {
+		return nil, errors.New("not enough arguments for format string")
+	}
+	val := f.args[f.listPos]
+	f.listPos++
+	if v, ok := val.(reflect.Value); ok && v.CanInterface() {
+		val = v.Interface()
+	}
+	return f.lazy.resolve(val)
+}
+
+// parsePercentSpec parses the conversion specifier starting at the '%' at format[i], and returns
+// it with the index just after it.
+func parsePercentSpec(format string, i int) (percentSpec, int, error) {
+	spec := percentSpec{width: -1, precision: -1}
+	end := len(format)
+	i++
+	if i < end && format[i] == '(' {
+		depth := 1
+		j := i + 1
+		for ; j < end && depth > 0; j++ {
+			if format[j] == '(' {
+				depth++
+			} else if format[j] == ')' {
+				depth--
+			}
+		}
+		if depth > 0 {
+			return spec, 0, errors.New("incomplete format key")
+		}
+		spec.key = format[i+1 : j-1]
+		spec.hasKey = true
+		i = j
+	}
+flags:
+	for ; i < end; i++ {
+		switch format[i] {
+		case '-':
+			spec.left = true
+		case '0':
+			spec.zero = true
+		case '+':
+			spec.plus = true
+		case ' ':
+			spec.space = true
+		case '#':
+			spec.alt = true
+		default:
+			break flags
+		}
+	}
+	spec.width, i = parsePercentNumber(format, i)
+	if i < end && format[i] == '.' {
+		spec.precision, i = parsePercentNumber(format, i+1)
+		if spec.precision == -1 {
+			spec.precision = 0
+		}
+	}
+	// Length modifiers are accepted and ignored, like in Python.
+	for i < end && (format[i] == 'h' || format[i] == 'l' || format[i] == 'L') {
+		i++
+	}
+	if i >= end {
+		return spec, 0, errors.New("incomplete format")
+	}
+	spec.conv = format[i]
+	if strings.IndexByte("sradiuoxXeEfFgGc%", spec.conv) < 0 {
+		r, _ := utf8.DecodeRuneInString(format[i:])
+		return spec, 0, Error("unsupported format character '{}' ({:#x}) at index {}", string(r), r, i)
+	}
+	return spec, i + 1, nil
+}
+
+// parsePercentNumber parses a width or precision, returning -1 if there isn't one, and
+// percentStarValue for a '*'.
+func parsePercentNumber(format string, i int) (int, int) {
+	if i < len(format) && format[i] == '*' {
+		return percentStarValue, i + 1
+	}
+	j := i
+	for j < len(format) && isDigit(format[j]) {
+		j++
+	}
+	if j == i {
+		return -1, i
+	}
+	n, err := strconv.Atoi(format[i:j])
+	if err != nil {
+		return -1, j
+	}
+	return n, j
+}
+
+// writePercent formats a single value according to a conversion specifier.
+func (f *ff) writePercent(spec *percentSpec, val interface{}) error {
+	if _, ok := val.(nilPath); ok {
+		f.writePercentString(&percentSpec{width: spec.width, left: spec.left, precision: -1}, f.cfg.nilPlaceholder())
+		return nil
+	}
+	switch spec.conv {
+	case 's':
+		s, err := f.cfg.formatValue(val)
+		if err != nil {
+			return err
+		}
+		f.writePercentString(spec, s)
+	case 'r', 'a':
+		var b buffer
+		p := newPyRepr(&b)
+		p.dataclass = true
+		p.omitZero = f.cfg.OmitZeroFields
+		p.write(valueOf(val))
+		s := string(b.contents)
+		if spec.conv == 'a' {
+			s = asciiEscape(s)
+		}
+		f.writePercentString(spec, s)
+	case 'c':
+		var s string
+		if n, ok := intOf(val); ok && n >= 0 && n <= utf8.MaxRune {
+			s = string(rune(n))
+		} else if str, ok := val.(string); ok && utf8.RuneCountInString(str) == 1 {
+			s = str
+		} else {
+			return Error("%c requires int or char, not {:t}", val)
+		}
+		f.writePercentString(spec, s)
+	case 'd', 'i', 'u', 'o', 'x', 'X':
+		truncate := spec.conv == 'd' || spec.conv == 'i' || spec.conv == 'u'
+		n, ok := percentInt(val, truncate)
+		if !ok && truncate {
+			return Error("%{} format: a number is required, not {:t}", string(spec.conv), val)
+		} else if !ok {
+			return Error("%{} format: an integer is required, not {:t}", string(spec.conv), val)
+		}
+		neg := n.Sign() < 0
+		n.Abs(n)
+		var digits, prefix string
+		switch spec.conv {
+		case 'o':
+			digits = n.Text(8)
+			if spec.alt {
+				prefix = "0o"
+			}
+		case 'x':
+			digits = n.Text(16)
+			if spec.alt {
+				prefix = "0x"
+			}
+		case 'X':
+			digits = strings.ToUpper(n.Text(16))
+			if spec.alt {
+				prefix = "0X"
+			}
+		default:
+			digits = n.Text(10)
+		}
+		if spec.precision > len(digits) {
+			digits = strings.Repeat("0", spec.precision-len(digits)) + digits
+		}
+		f.writePercentNumber(spec, neg, prefix, digits)
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		n, ok := numberOf(val)
+		if b, isBool := val.(bool); isBool {
+			n, ok = float64(boolToInt(b)), true
+		}
+		if !ok {
+			return Error("%{} format: a number is required, not {:t}", string(spec.conv), val)
+		}
+		neg := n < 0 || (n == 0 && math.Signbit(n))
+		n = math.Abs(n)
+		var digits string
+		switch {
+		case math.IsNaN(n):
+			digits = "nan"
+		case math.IsInf(n, 0):
+			digits = "inf"
+		default:
+			prec := spec.precision
+			if prec < 0 {
+				prec = 6
+			}
+			verb := "%." + strconv.Itoa(prec) + string(spec.conv)
+			if spec.alt {
+				verb = "%#." + strconv.Itoa(prec) + string(spec.conv)
+			}
+			digits = fmt.Sprintf(verb, n)
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			// Python doesn't zero pad inf and nan.
+			spec.zero = false
+			if spec.conv == 'E' || spec.conv == 'F' || spec.conv == 'G' {
+				digits = strings.ToUpper(digits)
+			}
+		}
+		f.writePercentNumber(spec, neg, "", digits)
+	}
+	return nil
+}
+
+// percentInt converts a value for the integer conversions. Bools are 0 or 1, and if truncate is
+// set, floats and other numbers are truncated towards zero, as Python does for the d, i, and u
+// conversions but not for o, x, and X.
+func percentInt(val interface{}, truncate bool) (*big.Int, bool) {
+	switch n := val.(type) {
+	case *big.Int:
+		if n != nil {
+			return new(big.Int).Set(n), true
+		}
+		return nil, false
+	case big.Int:
+		return new(big.Int).Set(&n), true
+	case bool:
+		return big.NewInt(int64(boolToInt(n))), true
+	}
+	v := valueOf(val)
+	if !v.IsValid() {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(v.Uint()), true
+	}
+	if !truncate {
+		return nil, false
+	}
+	if n, ok := numberOf(val); ok && !math.IsNaN(n) && !math.IsInf(n, 0) {
+		i, _ := big.NewFloat(math.Trunc(n)).Int(nil)
+		return i, true
+	}
+	return nil, false
+}
+
+// writePercentString writes a string, truncated to the precision and padded to the width.
+func (f *ff) writePercentString(spec *percentSpec, s string) {
+	if spec.precision >= 0 && utf8.RuneCountInString(s) > spec.precision {
+		i := 0
+		for n := 0; n < spec.precision; n++ {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		s = s[:i]
+	}
+	pad := spec.width - utf8.RuneCountInString(s)
+	if pad > 0 && !spec.left {
+		f.buf.WriteRepeatedString(" ", pad)
+	}
+	f.buf.WriteString(s)
+	if pad > 0 && spec.left {
+		f.buf.WriteRepeatedString(" ", pad)
+	}
+}
+
+// writePercentNumber writes a number's sign, prefix, and digits, padded to the width. Zero padding
+// goes between the prefix and the digits.
+func (f *ff) writePercentNumber(spec *percentSpec, neg bool, prefix, digits string) {
+	var sign string
+	switch {
+	case neg:
+		sign = "-"
+	case spec.plus:
+		sign = "+"
+	case spec.space:
+		sign = " "
+	}
+	pad := spec.width - len(sign) - len(prefix) - len(digits)
+	switch {
+	case pad <= 0:
+		f.buf.WriteString(sign + prefix + digits)
+	case spec.left:
+		f.buf.WriteString(sign + prefix + digits)
+		f.buf.WriteRepeatedString(" ", pad)
+	case spec.zero:
+		f.buf.WriteString(sign + prefix)
+		f.buf.WriteRepeatedString("0", pad)
+		f.buf.WriteString(digits)
+	default:
+		f.buf.WriteRepeatedString(" ", pad)
+		f.buf.WriteString(sign + prefix + digits)
+	}
+}
+
+// asciiEscape escapes the non-ASCII characters in a repr() string, like Python's ascii().
+func asciiEscape(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, c := range s {
+		switch {
+		case c < utf8.RuneSelf:
+			out = append(out, byte(c))
+		case c <= 0xff:
+			out = append(out, Must(`\x{:02x}`, c)...)
+		case c <= 0xffff:
+			out = append(out, Must(`\u{:04x}`, c)...)
+		default:
+			out = append(out, Must(`\U{:08x}`, c)...)
+		}
+	}
+	return string(out)
+}
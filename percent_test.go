@@ -0,0 +1,139 @@
+package pyfmt
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestPercentFormat(t *testing.T) {
+	type user struct {
+		Name   string
+		Logins int
+	}
+
+	tests := []struct {
+		fmtStr string
+		params []interface{}
+		want   string
+	}{
+		{"", []interface{}{}, ""},
+		{"100%%", []interface{}{}, "100%"},
+		{"%s logged in %d times", []interface{}{"bob", 3}, "bob logged in 3 times"},
+		{"%(Name)s logged in %(Logins)d times", []interface{}{user{"bob", 3}}, "bob logged in 3 times"},
+		{"%(user)s", []interface{}{map[string]string{"user": "amy"}}, "amy"},
+		{"%(u.Name)s", []interface{}{map[string]user{"u": {Name: "ann"}}}, "ann"},
+		{"%(a)s %(a)r", []interface{}{map[string]string{"a": "x"}}, "x 'x'"},
+		{"%(0)s %(1.a)s", []interface{}{map[string]interface{}{"0": "x", "1": map[string]int{"a": 2}}}, "x 2"},
+		{"%s %(a)s", []interface{}{map[string]int{"a": 1}}, "map[a:1] 1"},
+
+		// Strings
+		{"[%-10s]", []interface{}{"left"}, "[left      ]"},
+		{"[%10s]", []interface{}{"right"}, "[     right]"},
+		{"[%05s]", []interface{}{"ab"}, "[   ab]"},
+		{"[%.2s]", []interface{}{"abc"}, "[ab]"},
+		{"[%*s]", []interface{}{5, "ab"}, "[   ab]"},
+		{"[%*s]", []interface{}{-5, "ab"}, "[ab   ]"},
+		{"[%.*s]", []interface{}{1, "ab"}, "[a]"},
+		{"[%4s]", []interface{}{"日本"}, "[  日本]"},
+		{"%s", []interface{}{stringer(1)}, "custom stringer"},
+		{"%s", []interface{}{[]int{1}}, "[1]"},
+		{"%r", []interface{}{"it's"}, `"it's"`},
+		{"%r", []interface{}{[]interface{}{1, "a", nil}}, "[1, 'a', None]"},
+		{"%r", []interface{}{point{1, 2}}, "point(x=1, y=2)"},
+		{"%a", []interface{}{"é☺"}, `'\xe9\u263a'`},
+		{"%c%c", []interface{}{'h', "i"}, "hi"},
+		{"[%3c]", []interface{}{65}, "[  A]"},
+
+		// Integers
+		{"%d %i %u", []interface{}{1, -2, 3}, "1 -2 3"},
+		{"%d", []interface{}{3.99}, "3"},
+		{"%d", []interface{}{-3.99}, "-3"},
+		{"%d", []interface{}{true}, "1"},
+		{"%d", []interface{}{uint64(math.MaxUint64)}, "18446744073709551615"},
+		{"%d", []interface{}{big.NewInt(-12)}, "-12"},
+		{"%5d|%-5d|%05d", []interface{}{42, 42, -42}, "   42|42   |-0042"},
+		{"%+d % d", []interface{}{5, 5}, "+5  5"},
+		{"%.3d", []interface{}{5}, "005"},
+		{"%o %#o", []interface{}{8, 8}, "10 0o10"},
+		{"%x %#x %X %#X", []interface{}{255, 255, 255, 255}, "ff 0xff FF 0XFF"},
+		{"%#08x", []interface{}{255}, "0x0000ff"},
+		{"%x", []interface{}{-255}, "-ff"},
+		{"%x %o", []interface{}{true, false}, "1 0"},
+		{"%u %i", []interface{}{3.7, float32(-2.5)}, "3 -2"},
+		{"%ld %hd", []interface{}{1, 2}, "1 2"},
+
+		// Floats
+		{"%f", []interface{}{1.5}, "1.500000"},
+		{"%.2f", []interface{}{3.14159}, "3.14"},
+		{"%08.2f", []interface{}{-3.14159}, "-0003.14"},
+		{"%+.1f", []interface{}{2}, "+2.0"},
+		{"%e", []interface{}{12345.678}, "1.234568e+04"},
+		{"%.2E", []interface{}{0.00012}, "1.20E-04"},
+		{"%g", []interface{}{0.1234567}, "0.123457"},
+		{"%g", []interface{}{1e20}, "1e+20"},
+		{"%#g", []interface{}{1.0}, "1.00000"},
+		{"%G", []interface{}{1e-10}, "1E-10"},
+		{"%f %F", []interface{}{math.Inf(1), math.Inf(-1)}, "inf -INF"},
+		{"%05f", []interface{}{math.NaN()}, "  nan"},
+		{"%.1f", []interface{}{big.NewFloat(2.25)}, "2.2"},
+	}
+
+	for _, test := range tests {
+		got, err := PercentFmt(test.fmtStr, test.params...)
+		if err != nil {
+			t.Error(Must("PercentFmt({fmtStr}, {params}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("PercentFmt({fmtStr}, {params}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestPercentFormatPyLiterals(t *testing.T) {
+	f := &Formatter{PyLiterals: true}
+	if got := f.PercentMust("%s %s", true, []string{"a"}); got != "True ['a']" {
+		t.Error(Must("PercentMust() = {}, Want: True ['a']", got))
+	}
+}
+
+func TestPercentFormatDelimiters(t *testing.T) {
+	f := &Formatter{LeftDelim: "<<", RightDelim: ">>"}
+	if got := f.PercentMust("%s|%5s|%r", 42, "{}", "a"); got != "42|   {}|'a'" {
+		t.Error(Must("PercentMust() = {}, Want: 42|   {{}}|'a'", got))
+	}
+}
+
+func TestPercentFormatError(t *testing.T) {
+	tests := []struct {
+		fmtStr string
+		params []interface{}
+	}{
+		{"%", []interface{}{}},
+		{"%5", []interface{}{1}},
+		{"%y", []interface{}{1}},
+		{"%s %s", []interface{}{1}},
+		{"%s", []interface{}{1, 2}},
+		{"no specifiers", []interface{}{1}},
+		{"%d", []interface{}{"1"}},
+		{"%(a)s %s", []interface{}{map[string]int{"a": 1}}},
+		{"%(0)s", []interface{}{map[string]int{"a": 1}}},
+		{"%x", []interface{}{3.7}},
+		{"%X", []interface{}{3.0}},
+		{"%o", []interface{}{big.NewFloat(8)}},
+		{"%f", []interface{}{"1"}},
+		{"%c", []interface{}{"ab"}},
+		{"%(a", []interface{}{map[string]int{"a": 1}}},
+		{"%(b)s", []interface{}{map[string]int{"a": 1}}},
+		{"%(a)s", []interface{}{map[string]int{"a": 1}, 2}},
+		{"%(a)*d", []interface{}{map[string]int{"a": 1}}},
+		{"%*d", []interface{}{"x", 1}},
+	}
+
+	for _, test := range tests {
+		_, err := PercentFmt(test.fmtStr, test.params...)
+		if err == nil {
+			t.Error(Must("PercentFmt({fmtStr}, {params}) did not error when expected!", test))
+		}
+	}
+}
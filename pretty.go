@@ -0,0 +1,202 @@
+package pyfmt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultPrettyIndent is the number of spaces each level is indented by with the 'p' type when no
+// width is given.
+const defaultPrettyIndent = 2
+
+// prettyPrinter writes values as an indented tree, one struct field, list item, or map entry per
+// line.
+type prettyPrinter struct {
+	buf      *buffer
+	indent   string
+	maxDepth int
+	maxItems int
+	seen     visited
+}
+
+// renderPretty renders the value as a multi-line tree. The width sets the indent, and the precision
+// sets the maximum depth, overriding the Formatter's PrettyMaxDepth.
+func (r *render) renderPretty() error {
+	indent := defaultPrettyIndent
+	if r.minWidth != "" {
+		width, err := strconv.Atoi(r.minWidth)
+		if err != nil {
+			return Error("Can't convert width {} to int", r.minWidth)
+		}
+		indent = width
+	}
+	p := prettyPrinter{
+		buf:      r.buf,
+		indent:   strings.Repeat(" ", indent),
+		maxDepth: r.cfg.PrettyMaxDepth,
+		maxItems: r.cfg.PrettyMaxItems,
+		seen:     visited{},
+	}
+	if r.precision != "" {
+		depth, err := strconv.Atoi(r.precision[1:])
+		if err != nil {
+			return Error("Can't convert precision {} to int", r.precision)
+		}
+		p.maxDepth = depth
+	}
+	p.write(valueOf(r.val), 0)
+	return nil
+}
+
+func (p *prettyPrinter) newline(depth int) {
+	p.buf.WriteString("\n")
+	p.buf.WriteRepeatedString(p.indent, depth)
+}
+
+// tooDeep returns true if a container at this depth should be elided.
+func (p *prettyPrinter) tooDeep(depth int) bool {
+	return p.maxDepth > 0 && depth >= p.maxDepth
+}
+
+// elide returns true if the container has more items than should be printed at item i, and
+// writes the elision marker if so.
+func (p *prettyPrinter) elide(i, depth int) bool {
+	if p.maxItems > 0 && i >= p.maxItems {
+		p.newline(depth + 1)
+		p.buf.WriteString("...")
+		return true
+	}
+	return false
+}
+
+func (p *prettyPrinter) write(v reflect.Value, depth int) {
+	b := p.buf
+	if !v.IsValid() {
+		b.WriteString("<nil>")
+		return
+	}
+	if hasCustomFormat(v) {
+		fmt.Fprint(b, v.Interface())
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("<nil>")
+			return
+		}
+		p.write(v.Elem(), depth)
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("<nil>")
+			return
+		}
+		if !p.seen.enter(v) {
+			b.WriteString("<cycle>")
+			return
+		}
+		b.WriteString("&")
+		p.write(v.Elem(), depth)
+		p.seen.leave(v)
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Struct:
+		p.writeStruct(v, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Len() > 0 {
+			if !p.seen.enter(v) {
+				b.WriteString("<cycle>")
+				return
+			}
+			defer p.seen.leave(v)
+		}
+		p.writeList(v, depth)
+	case reflect.Map:
+		if v.Len() > 0 {
+			if !p.seen.enter(v) {
+				b.WriteString("<cycle>")
+				return
+			}
+			defer p.seen.leave(v)
+		}
+		p.writeMap(v, depth)
+	default:
+		fmt.Fprint(b, v)
+	}
+}
+
+func (p *prettyPrinter) writeStruct(v reflect.Value, depth int) {
+	b := p.buf
+	b.WriteString(v.Type().String())
+	if v.NumField() == 0 {
+		b.WriteString("{}")
+		return
+	}
+	if p.tooDeep(depth) {
+		b.WriteString("{...}")
+		return
+	}
+	b.WriteString("{")
+	for i := 0; i < v.NumField(); i++ {
+		if p.elide(i, depth) {
+			break
+		}
+		p.newline(depth + 1)
+		b.WriteString(v.Type().Field(i).Name)
+		b.WriteString(": ")
+		p.write(v.Field(i), depth+1)
+		b.WriteString(",")
+	}
+	p.newline(depth)
+	b.WriteString("}")
+}
+
+func (p *prettyPrinter) writeList(v reflect.Value, depth int) {
+	b := p.buf
+	if v.Len() == 0 {
+		b.WriteString("[]")
+		return
+	}
+	if p.tooDeep(depth) {
+		b.WriteString("[...]")
+		return
+	}
+	b.WriteString("[")
+	for i := 0; i < v.Len(); i++ {
+		if p.elide(i, depth) {
+			break
+		}
+		p.newline(depth + 1)
+		p.write(v.Index(i), depth+1)
+		b.WriteString(",")
+	}
+	p.newline(depth)
+	b.WriteString("]")
+}
+
+func (p *prettyPrinter) writeMap(v reflect.Value, depth int) {
+	b := p.buf
+	if v.Len() == 0 {
+		b.WriteString("{}")
+		return
+	}
+	if p.tooDeep(depth) {
+		b.WriteString("{...}")
+		return
+	}
+	b.WriteString("{")
+	for i, key := range sortedKeys(v) {
+		if p.elide(i, depth) {
+			break
+		}
+		p.newline(depth + 1)
+		p.write(key, depth+1)
+		b.WriteString(": ")
+		p.write(v.MapIndex(key), depth+1)
+		b.WriteString(",")
+	}
+	p.newline(depth)
+	b.WriteString("}")
+}
@@ -0,0 +1,79 @@
+package pyfmt
+
+import (
+	"testing"
+)
+
+type prettyConfig struct {
+	Name   string
+	Ports  []int
+	Labels map[string]string
+	Parent *prettyConfig
+}
+
+func TestPrettyFormat(t *testing.T) {
+	loop := &prettyConfig{Name: "loop"}
+	loop.Parent = loop
+
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{:p}", 42, "42"},
+		{"{:p}", "str", `"str"`},
+		{"{:p}", []int{}, "[]"},
+		{"{:p}", map[string]int{}, "{}"},
+		{"{:p}", []int{1, 2}, "[\n  1,\n  2,\n]"},
+		{"{:4p}", []int{1}, "[\n    1,\n]"},
+		{"{:p}", map[string]int{"b": 2, "a": 1}, "{\n  \"a\": 1,\n  \"b\": 2,\n}"},
+		{"{:p}", map[int][]int{10: {1}, 9: nil}, "{\n  9: [],\n  10: [\n    1,\n  ],\n}"},
+		{"{:p}", prettyConfig{Name: "a", Ports: []int{80}}, `pyfmt.prettyConfig{
+  Name: "a",
+  Ports: [
+    80,
+  ],
+  Labels: {},
+  Parent: <nil>,
+}`},
+		{"{:p}", loop, `&pyfmt.prettyConfig{
+  Name: "loop",
+  Ports: [],
+  Labels: {},
+  Parent: <cycle>,
+}`},
+		{"{:.1p}", [][]int{{1}, {}}, "[\n  [...],\n  [],\n]"},
+		{"{:.1p}", prettyConfig{Parent: &prettyConfig{}}, `pyfmt.prettyConfig{
+  Name: "",
+  Ports: [],
+  Labels: {},
+  Parent: &pyfmt.prettyConfig{...},
+}`},
+		{"{:p}", []stringer{1}, "[\n  custom stringer,\n]"},
+		{"{cfg.Ports:p}", map[string]prettyConfig{"cfg": {Ports: []int{1}}}, "[\n  1,\n]"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = \n{1}\nWant:\n{want}", test, got))
+		}
+	}
+}
+
+func TestPrettyMaxItems(t *testing.T) {
+	f := &Formatter{PrettyMaxItems: 2, PrettyMaxDepth: 2}
+	got := f.Must("{:p}", [][]int{{1, 2, 3}, {4}, {5}})
+	want := "[\n  [\n    1,\n    2,\n    ...\n  ],\n  [\n    4,\n  ],\n  ...\n]"
+	if got != want {
+		t.Error(Must("Must({{:p}}) = \n{}\nWant:\n{}", got, want))
+	}
+	got = f.Must("{:p}", [][][]int{{{1}}})
+	want = "[\n  [\n    [...],\n  ],\n]"
+	if got != want {
+		t.Error(Must("Must({{:p}}) = \n{}\nWant:\n{}", got, want))
+	}
+}
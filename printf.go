@@ -0,0 +1,59 @@
+package pyfmt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// printfFlags are the flags allowed in a printf verb passed through to fmt.
+	printfFlags = "+-# 0"
+	// printfVerbs are the fmt verbs that can be passed through.
+	printfVerbs = "vTtbcdoOqxXUeEfFgGsp"
+)
+
+// isPrintfSpec returns true if a format spec is a printf verb to pass through to fmt, like "%q" or
+// "%-08.3f". A lone "%" is the percent type, and a '%' followed by an alignment is a fill character.
+func isPrintfSpec(spec string) bool {
+	return len(spec) > 1 && spec[0] == '%' && strings.IndexByte("<>=^", spec[1]) < 0
+}
+
+// parsePrintf checks that a printf verb is well formed: a single '%', followed by optional flags,
+// width, and precision, and then a verb.
+func parsePrintf(spec string) error {
+	i := 1
+	for i < len(spec) && strings.IndexByte(printfFlags, spec[i]) >= 0 {
+		i++
+	}
+	for i < len(spec) && isDigit(spec[i]) {
+		i++
+	}
+	if i < len(spec) && spec[i] == '.' {
+		i++
+		for i < len(spec) && isDigit(spec[i]) {
+			i++
+		}
+	}
+	if i != len(spec)-1 || strings.IndexByte(printfVerbs, spec[i]) < 0 {
+		return Error("Invalid printf verb: {}", spec)
+	}
+	return nil
+}
+
+// renderPrintf formats the value with fmt, using the printf verb as is. fmt writes a "%!" error
+// into the output if the verb doesn't suit the value, or an element or field of it, so that's turned
+// into a format error.
+func (r *render) renderPrintf() error {
+	str := fmt.Sprintf(r.printf, r.val)
+	verb := r.printf[len(r.printf)-1]
+	if strings.Contains(str, "%!"+string(verb)+"(") {
+		// %s and %v print strings unchanged, so a string could legitimately contain "%!".
+		v := valueOf(r.val)
+		if !((verb == 's' || verb == 'v') && v.IsValid() && v.Kind() == reflect.String) {
+			return Error("printf verb {} can't format {:t}", r.printf, r.val)
+		}
+	}
+	r.buf.WriteString(str)
+	return nil
+}
@@ -0,0 +1,66 @@
+package pyfmt
+
+import (
+	"testing"
+)
+
+func TestPrintfFormat(t *testing.T) {
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{:%q}", "a b", `"a b"`},
+		{"{:%x}", "a b", "612062"},
+		{"{:% x}", "a b", "61 20 62"},
+		{"{:%U}", '⌘', "U+2318"},
+		{"{:%-08.3f}|", 3.14159, "3.142   |"},
+		{"{:%08.3f}", -3.14159, "-003.142"},
+		{"{:%+d}", 5, "+5"},
+		{"{:%#v}", []int{1}, "[]int{1}"},
+		{"{0[0]:%5.1s}|", []string{"abc"}, "    a|"},
+		{"{:%v}", "%!v(odd)", "%!v(odd)"},
+		{"{:%s}", "a %!s(odd) b", "a %!s(odd) b"},
+		{"{:%d}", []int{1, 2}, "[1 2]"},
+		{"{:%d}", struct{ A, B int }{1, 2}, "{1 2}"},
+		// A '%' followed by an alignment is a fill character, and a lone '%' is the percent type.
+		{"{:%<5}", 1, "1%%%%"},
+		{"{:%}", 0.5, "50.000000%"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestPrintfFormatError(t *testing.T) {
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+	}{
+		{"{:%d}", "text"},
+		{"{:%s}", 5},
+		{"{:%y}", 5},
+		{"{:%*d}", 5},
+		{"{:%d%d}", 5},
+		{"{:%[1]d}", 5},
+		{"{:%.3.f}", 5.0},
+		{"{:%d }", 5},
+		{"{:%d}", struct{ A, B string }{"a", "b"}},
+		{"{:%d}", []string{"a"}},
+		{"{:%x}", []interface{}{1, true}},
+	}
+
+	for _, test := range tests {
+		_, err := Fmt(test.fmtStr, test.param)
+		if err == nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) did not error when expected!", test))
+		}
+	}
+}
@@ -2,6 +2,7 @@ package pyfmt
 
 import (
 	"errors"
+	"strings"
 	"sync"
 	"unicode/utf8"
 )
@@ -79,6 +80,7 @@ func (b *buffer) WriteAlignedString(s string, align int, width int64, fillChar r
 
 // What type of numbering is being used to access fields. {} is automatic, {0} is manual.
 type numbering int
+
 const (
 	unknown numbering = iota
 	automatic
@@ -90,9 +92,17 @@ type ff struct {
 	buf buffer
 
 	// args is the list of arguments passed to Fmt.
-	args    []interface{}
+	args []interface{}
+	// src is the Args passed to FmtArgs. If nil, arguments are looked up in args, using values.
+	src    Args
+	values valueArgs
+	// lazy holds the LazyValues computed during this format.
+	lazy    lazyCache
 	listPos int
-	numb numbering
+	numb    numbering
+
+	// cfg holds the options of the Formatter that started this format.
+	cfg *Formatter
 
 	// render renders format parameters
 	r render
@@ -103,24 +113,87 @@ var ffFree = sync.Pool{
 }
 
 // newFormater creates a new ff struct.
-func newFormater() *ff {
+func newFormater(cfg *Formatter) *ff {
 	f := ffFree.Get().(*ff)
 	f.listPos = 0
 	f.numb = unknown
-	f.r.init(&f.buf)
+	f.cfg = cfg
+	f.r.init(&f.buf, cfg)
 	return f
 }
 
 func (f *ff) free() {
 	f.buf.contents = f.buf.contents[:0]
 	f.args = f.args[:0]
+	f.src = nil
+	f.values.elems = nil
+	f.lazy.values = nil
 	f.listPos = 0
 	f.numb = unknown
+	f.cfg = nil
+	f.r.cfg = nil
+	f.r.val = nil
 	ffFree.Put(f)
 }
 
 // doFormat parses the string, and executes a format command. Stores the output in ff's buf.
 func (f *ff) doFormat(format string) error {
+	fm := f.cfg
+	if fm.LeftDelim == "" && fm.RightDelim == "" && fm.LeftEscape == "" && fm.RightEscape == "" {
+		return f.doFormatBraces(format)
+	}
+	left, right, leftEsc, rightEsc := fm.delims()
+	end := len(format)
+	for i := 0; i < end; {
+		cachei := i
+		// First, get to an opening delimiter, writing out escaped delimiters along the way.
+		for i < end {
+			if format[i] != left[0] && format[i] != right[0] && format[i] != leftEsc[0] && format[i] != rightEsc[0] {
+				i++
+				continue
+			}
+			rest := format[i:]
+			var esc, delim string
+			if strings.HasPrefix(rest, leftEsc) {
+				esc, delim = leftEsc, left
+			} else if strings.HasPrefix(rest, rightEsc) {
+				esc, delim = rightEsc, right
+			} else if strings.HasPrefix(rest, left) {
+				break
+			} else if strings.HasPrefix(rest, right) {
+				// A closing delimiter before an opening one is an error, unless it's escaped.
+				return Error("Single '{}' encountered in format string", right)
+			} else {
+				i++
+				continue
+			}
+			f.buf.WriteString(format[cachei:i])
+			f.buf.WriteString(delim)
+			i += len(esc)
+			cachei = i
+		}
+		if i > cachei {
+			f.buf.WriteString(format[cachei:i])
+		}
+		if i >= end {
+			break
+		}
+		i += len(left)
+		closing := fieldEnd(format[i:], right)
+		if closing < 0 {
+			return Error("Single '{}' encountered in format string", left)
+		}
+		if err := f.formatField(format[i:i+closing], left, right); err != nil {
+			return err
+		}
+		i += closing + len(right)
+	}
+	return nil
+}
+
+// doFormatBraces is doFormat for the default delimiters, '{' and '}', escaped by doubling them,
+// which is the common case, so is kept fast.
+func (f *ff) doFormatBraces(format string) error {
 	end := len(format)
 	for i := 0; i < end; {
 		cachei := i
@@ -150,38 +223,63 @@ func (f *ff) doFormat(format string) error {
 			i++
 			continue
 		}
-		cachei = i
-		for i < end && format[i] != '}' {
-			i++
-		}
-		if i >= end || format[i] != '}' {
+		closing := fieldEnd(format[i:], "}")
+		if closing < 0 {
 			return errors.New("Single '{' encountered in format string")
 		}
-		field := format[cachei:i]
-		var err error
-		name, format := split(field, ':')
-		f.r.val, err = f.getArg(name)
-		if err != nil {
+		if err := f.formatField(format[i:i+closing], "{", "}"); err != nil {
 			return err
 		}
-		if formatter, ok := f.r.val.(PyFormatter); ok {
-			formatted, err := formatter.PyFormat(format)
-			if err != nil {
-				return err
-			}
-			f.buf.WriteString(formatted)
+		i += closing + 1
+	}
+	return nil
+}
+
+// formatField looks up and writes a single replacement field, the text between the delimiters.
+func (f *ff) formatField(field, left, right string) error {
+	name, format := splitField(field)
+	key, def, hasDefault := splitOutsideBrackets(name, '|')
+	var err error
+	if hasDefault && key != "" {
+		// Names may contain a '|', so the whole name is looked up first, and the '|' only starts a
+		// default if that fails.
+		if f.r.val, err = f.getArg(name); err == nil {
+			return f.writeValue(format)
+		}
+	}
+	name = key
+	f.r.val, err = f.getArg(name)
+	if missing, ok := err.(missingError); ok {
+		if hasDefault {
+			f.r.val, err = def, nil
+		} else if f.cfg.MissingKey != MissingKeyError {
+			f.writeMissing(name, field, left, right)
+			return nil
 		} else {
-			f.r.clearFlags()
-			if err = f.r.parseFlags(format); err != nil {
-				return err
-			}
-			if err = f.r.render(); err != nil {
-				return err
-			}
+			err = missing.error
 		}
-		i++
 	}
-	return nil
+	if err != nil {
+		return err
+	}
+	return f.writeValue(format)
+}
+
+// writeValue writes the value in f.r.val, formatted with the format spec.
+func (f *ff) writeValue(format string) error {
+	if formatter, ok := f.r.val.(PyFormatter); ok {
+		formatted, err := formatter.PyFormat(format)
+		if err != nil {
+			return err
+		}
+		f.buf.WriteString(formatted)
+		return nil
+	}
+	f.r.clearFlags()
+	if err := f.r.parseFlags(format); err != nil {
+		return err
+	}
+	return f.r.render()
 }
 
 // Split splits a string on a rune, returning slices pointing to the half before that rune, and
@@ -198,6 +296,73 @@ func split(s string, sep rune) (string, string) {
 	return s[:], s[len(s):]
 }
 
+// splitField splits a replacement field into its field name and format spec, at the first ':'
+// that isn't inside square brackets, so that a slice range like "{items[1:3]}" or a quoted key
+// like "{labels['a:b']}" is part of the name.
+func splitField(field string) (string, string) {
+	name, format, _ := splitOutsideBrackets(field, ':')
+	return name, format
+}
+
+// splitOutsideBrackets splits a string at the first sep that isn't inside square brackets or a
+// quoted key, returning false if there isn't one.
+func splitOutsideBrackets(s string, sep byte) (string, string, bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if depth > 0 {
+				if _, n, err := unquoteKey(s[i:]); err == nil {
+					i += n - 1
+				}
+			}
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				return s[:i], s[i+1:], true
+			}
+		}
+	}
+	return s, "", false
+}
+
+// fieldEnd returns the index of the right delimiter that ends the replacement field at the start
+// of s, or -1 if there isn't one. Quoted keys in square brackets may contain the delimiter, so are
+// skipped over.
+func fieldEnd(s, right string) int {
+	end := strings.Index(s, right)
+	if end < 0 || strings.IndexByte(s[:end], '[') < 0 {
+		return end
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case depth > 0 && (s[i] == '"' || s[i] == '\''):
+			if _, n, err := unquoteKey(s[i:]); err == nil {
+				i += n - 1
+			}
+		case strings.HasPrefix(s[i:], right):
+			return i
+		case s[i] == '[':
+			depth++
+		case s[i] == ']' && depth > 0:
+			depth--
+		case s[i] == ':' && depth == 0:
+			// Quotes and brackets in the format spec are fill characters, not keys.
+			if end = strings.Index(s[i:], right); end < 0 {
+				return -1
+			}
+			return i + end
+		}
+	}
+	return -1
+}
+
 func (f *ff) getArg(argName string) (interface{}, error) {
 	if f.numb == unknown {
 		if argName == "" {
@@ -212,18 +377,119 @@ func (f *ff) getArg(argName string) (interface{}, error) {
 		if argName != "" && f.numb == automatic {
 			return nil, Error("cannot switch from automatic field numbering to manual field specification")
 		}
-	} 
-	val, err := getElement(argName, f.listPos, f.args...)
+	}
+	src := f.src
+	if src == nil {
+		f.values.elems = f.args
+		src = &f.values
+	}
+	val, err := f.cfg.lookupArg(argName, f.listPos, src, &f.lazy)
 	if argName == "" {
 		f.listPos++
 	}
 	return val, err
 }
 
+// Formatter holds options that change how values are formatted. The zero value formats exactly
+// like the package-level Fmt, Must, and Error functions, and a Formatter may be used concurrently
+// as long as its options aren't modified.
+type Formatter struct {
+	// PyLiterals renders bools, nil, slices, arrays and maps as the equivalent Python literals
+	// (True, None, ['a', 'b'], {'a': 1}) instead of using Go's default formatting.
+	PyLiterals bool
+
+	// OmitZeroFields skips struct fields holding their zero value when printing structs with the
+	// 'R' type.
+	OmitZeroFields bool
+
+	// PrettyMaxDepth limits how many levels deep the 'p' type prints nested values, eliding deeper
+	// values with "...". Zero means no limit.
+	PrettyMaxDepth int
+
+	// PrettyMaxItems limits how many items of each struct, slice, array, or map the 'p' type prints,
+	// eliding the rest with "...". Zero means no limit.
+	PrettyMaxItems int
+
+	// Valuers unwraps values implementing database/sql/driver.Valuer (e.g., sql.NullInt64) to the
+	// value they hold before formatting, so that the format spec applies to the underlying value.
+	Valuers bool
+
+	// NullText is printed in place of a Valuer that holds nil (SQL NULL). If empty, the nil is
+	// printed like any other nil value.
+	NullText string
+
+	// TextMarshalers prints values implementing encoding.TextMarshaler using their MarshalText
+	// method, in preference to their String method.
+	TextMarshalers bool
+
+	// Speller spells out numbers for the 'ordinal' and 'words' types. If nil, English is used.
+	Speller NumberSpeller
+
+	// DisableMethods stops field names from calling methods, e.g. when formatting untrusted format
+	// strings. By default, a field name that doesn't match a field, key, or index calls the
+	// exported method with that name, if it takes no arguments and returns a value, or a value and
+	// an error.
+	DisableMethods bool
+
+	// JSONTags lets field names match a struct field by the name in its json tag, if its pyfmt tag
+	// doesn't name it.
+	JSONTags bool
+
+	// IgnoreFieldCase lets field names match struct fields ignoring case, if no field matches
+	// exactly.
+	IgnoreFieldCase bool
+
+	// NilSafe prints NilPlaceholder in place of a value whose field name goes through a nil pointer,
+	// map, interface, or slice, instead of returning an error, and in place of nil values and nil
+	// pointers.
+	NilSafe bool
+
+	// NilPlaceholder is printed for nil values in nil-safe mode. If empty, "<nil>" is used.
+	NilPlaceholder string
+
+	// MissingKey says what to print in place of a field name that can't be found, instead of
+	// returning an error. Fields with a default, like "{name|default}", print the default instead.
+	MissingKey MissingKeyPolicy
+
+	// LeftDelim and RightDelim open and close replacement fields, e.g. "<<" and ">>", which is
+	// useful when formatting text full of literal braces. If empty, '{' and '}' are used.
+	LeftDelim, RightDelim string
+
+	// LeftEscape and RightEscape are written in the format string to output a literal LeftDelim or
+	// RightDelim. If empty, the delimiter doubled is used, e.g. "{{" and "}}".
+	LeftEscape, RightEscape string
+}
+
+// delims returns the Formatter's delimiters and their escapes, filling in the defaults.
+func (fm *Formatter) delims() (left, right, leftEsc, rightEsc string) {
+	left, right, leftEsc, rightEsc = fm.LeftDelim, fm.RightDelim, fm.LeftEscape, fm.RightEscape
+	if left == "" {
+		left = "{"
+	}
+	if right == "" {
+		right = "}"
+	}
+	if leftEsc == "" {
+		leftEsc = left + left
+	}
+	if rightEsc == "" {
+		rightEsc = right + right
+	}
+	return left, right, leftEsc, rightEsc
+}
+
+// std is the Formatter used by the package-level functions.
+var std = &Formatter{}
+
 // Fmt is the equivalent of Python's string.format() function. Takes a list of possible elements
 // to use in formatting, and substitutes them.
 func Fmt(format string, a ...interface{}) (string, error) {
-	f := newFormater()
+	return std.Fmt(format, a...)
+}
+
+// Fmt is like the package-level Fmt, but uses the Formatter's options.
+func (fm *Formatter) Fmt(format string, a ...interface{}) (string, error) {
+	f := newFormater(fm)
 	defer f.free()
 	f.args = a
 	err := f.doFormat(format)
@@ -234,6 +500,35 @@ func Fmt(format string, a ...interface{}) (string, error) {
 	return s, nil
 }
 
+// formatValue formats a single value as if by "{}", whatever the Formatter's delimiters are.
+func (fm *Formatter) formatValue(val interface{}) (string, error) {
+	f := newFormater(fm)
+	defer f.free()
+	f.r.val = val
+	if err := f.writeValue(""); err != nil {
+		return "", err
+	}
+	return string(f.buf.contents), nil
+}
+
+// Must is like Formatter.Fmt, but panics on error.
+func (fm *Formatter) Must(format string, a ...interface{}) string {
+	s, err := fm.Fmt(format, a...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Error is like Formatter.Fmt, but returns an error.
+func (fm *Formatter) Error(format string, a ...interface{}) error {
+	s, err := fm.Fmt(format, a...)
+	if err != nil {
+		return Error("error formatting {}: {}", s, err)
+	}
+	return errors.New(s)
+}
+
 // Must is like Fmt, but panics on error.
 func Must(format string, a ...interface{}) string {
 	s, err := Fmt(format, a...)
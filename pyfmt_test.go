@@ -44,6 +44,10 @@ func TestBasicFormat(t *testing.T) {
 		{"0b{:b}", []interface{}{3}, "0b11"},
 		{"{:#x}", []interface{}{42}, "0x2a"},
 		{"{bar.baz.Bazzle[0]}", []interface{}{pointyMap()}, "1"},
+		{"{[-1]}", []interface{}{[]string{"a", "b", "c"}}, "c"},
+		{"{[1:]}", []interface{}{[]string{"a", "b", "c"}}, "[b c]"},
+		{"{[:3]:>5}|{0[-2:]:x}", []interface{}{"abcdef"}, "  abc|6566"},
+		{`{0["a:b"]:>4}{0['x]:']}`, []interface{}{map[string]int{"a:b": 1, "x]:": 2}}, "   12"},
 	}
 
 	for _, test := range tests {
@@ -129,6 +133,42 @@ func TestSingleFormat(t *testing.T) {
 		{"{::=#10X}", -1, "-0X::::::1"},
 		{"{:10X}", 0, "         0"},
 
+		// Grouping
+		{"{:,}", 1234567, "1,234,567"},
+		{"{:_d}", -1234567, "-1_234_567"},
+		{"{:,}", 123, "123"},
+		{"{:>12,}", 1234567, "   1,234,567"},
+		{"{:_x}", 0xdeadbeef, "dead_beef"},
+		{"{:#_b}", 0x1f, "0b1_1111"},
+		{"{:,.2f}", 1234567.891, "1,234,567.89"},
+		{"{:,.1%}", 12.5, "1,250.0%"},
+		{"{:010,}", 1234, "00,001,234"},
+		{"{:08,}", 1234, "0,001,234"},
+		{"{:07,}", 1, "000,001"},
+		{"{:010,}", -1234, "-0,001,234"},
+		{"{: 010,}", 1234, " 0,001,234"},
+		{"{:0=10,}", 1234, "00,001,234"},
+		{"{:0>10,}", 1234, "000001,234"},
+		{"{:012,.1f}", 1234.5, "00,001,234.5"},
+		{"{:015,.2%}", 12.34, "000,001,234.00%"},
+		{"{:#012_x}", 0x12345, "0x0_0001_2345"},
+		{"{:011_b}", 5, "0_0000_0101"},
+
+		// Two's complement
+		{"{:x~}", int8(-1), "ff"},
+		{"{:x}", int8(-1), "-1"},
+		{"{:#x~}", int16(-2), "0xfffe"},
+		{"{:X~12}", -1, "FFF"},
+		{"{:b~4}", -8, "1000"},
+		{"{:b~4}", 15, "1111"},
+		{"{:#_x~}", int32(-1), "0xffff_ffff"},
+		{"{:#010x~}", int8(-16), "0x000000f0"},
+		{"{:o~}", int8(-1), "377"},
+		{"{:x~}", int8(5), "5"},
+		{"{:x~}", uint8(255), "ff"},
+		{"{:x~64}", int64(-1), "ffffffffffffffff"},
+		{"{:~>6x~}", int8(-1), "~~~~ff"},
+
 		// Float tests
 		{"{:.0%}", 0.25, "25%"},
 		{"{:g}", math.Inf(+1), "+Inf"},
@@ -204,6 +244,17 @@ func TestSingleFormatError(t *testing.T) {
 		{"{", 0},
 		{"{[0]}", 0},
 		{"{[3]}", []string{"a", "b", "c"}},
+		{"{:,x}", 10},
+		{"{:,}", "text"},
+		{"{:_s}", 5},
+		{"{:,r}", 5},
+		{"{:,words}", 5},
+		{"{:d~}", -1},
+		{"{:x~}", "text"},
+		{"{:x~4}", 16},
+		{"{:x~4}", -9},
+		{"{:x~65}", -1},
+		{"{:x~0}", -1},
 	}
 
 	for _, test := range tests {
@@ -214,6 +265,64 @@ func TestSingleFormatError(t *testing.T) {
 	}
 }
 
+func TestDelimiters(t *testing.T) {
+	angles := &Formatter{LeftDelim: "<<", RightDelim: ">>"}
+	dollar := &Formatter{LeftDelim: "${", RightDelim: "}", LeftEscape: "$${"}
+	escaped := &Formatter{LeftEscape: `\{`, RightEscape: `\}`}
+
+	tests := []struct {
+		fm     *Formatter
+		fmtStr string
+		params []interface{}
+		want   string
+	}{
+		{angles, `{"id": <<>>, "tags": {}}`, []interface{}{7}, `{"id": 7, "tags": {}}`},
+		{angles, "<<0:<5>>|<<0:{^5>>", []interface{}{1}, "1    |{{1{{"},
+		{angles, "<<:>5>>", []interface{}{1}, "    1"},
+		{angles, "<<<<>>>>", []interface{}{}, "<<>>"},
+		{angles, "<<<<<<>>>>>>", []interface{}{1}, "<<1>>"},
+		{dollar, "func f() { return ${0} }}", []interface{}{1}, "func f() { return 1 }"},
+		{dollar, "$${x}}", []interface{}{}, "${x}"},
+		{escaped, `\{{}\}`, []interface{}{1}, "{1}"},
+		{escaped, "{}", []interface{}{"x"}, "x"},
+		{std, "{labels['a}b']}", []interface{}{map[string]interface{}{"labels": map[string]int{"a}b": 1}}}, "1"},
+		{std, `{0["}"]:>3}|{0[x]}`, []interface{}{map[string]string{"}": "a", "x": "b"}}, "  a|b"},
+		{std, "{0:[^3}", []interface{}{1}, "[1["},
+		{angles, "<<0['>>']>>", []interface{}{map[string]int{">>": 2}}, "2"},
+	}
+
+	for _, test := range tests {
+		got, err := test.fm.Fmt(test.fmtStr, test.params...)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {params}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {params}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestDelimitersError(t *testing.T) {
+	angles := &Formatter{LeftDelim: "<<", RightDelim: ">>"}
+
+	tests := []struct {
+		fmtStr  string
+		wantErr string
+	}{
+		{"<<", "Single '<<' encountered in format string"},
+		{"<<0", "Single '<<' encountered in format string"},
+		{"a >> b", "Single '>>' encountered in format string"},
+		{"<<0}", "Single '<<' encountered in format string"},
+	}
+
+	for _, test := range tests {
+		_, err := angles.Fmt(test.fmtStr, 1)
+		if err == nil || err.Error() != test.wantErr {
+			t.Error(Must("Fmt({fmtStr}) error = {1}, Want: {wantErr}", test, err))
+		}
+	}
+}
+
 func BenchmarkPrintEmptyParallel(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
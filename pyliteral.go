@@ -0,0 +1,328 @@
+package pyfmt
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pyLiteral rewrites the value being rendered into its Python form. Bools become True/False, or
+// 1/0 under the numeric types, nil becomes None, and slices, arrays, and maps become Python list
+// and dict literals. Values that fmt has a custom formatter for are left alone.
+func (r *render) pyLiteral() {
+	switch r.renderVerb {
+	case "v", "+v":
+		if s, ok := pyLiteralString(r.val); ok {
+			r.val = s
+		}
+	case "b", "d", "o", "x", "X":
+		if v := valueOf(r.val); v.IsValid() && v.Kind() == reflect.Bool {
+			r.val = boolToInt(v.Bool())
+		}
+	case "e", "E", "f", "F", "g", "G":
+		if v := valueOf(r.val); v.IsValid() && v.Kind() == reflect.Bool {
+			r.val = float64(boolToInt(v.Bool()))
+		}
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// pyLiteralString returns the Python literal for a value, and whether the value has a Python
+// literal that differs from how Go would print it.
+func pyLiteralString(val interface{}) (string, bool) {
+	v := valueOf(val)
+	if hasCustomFormat(v) {
+		return "", false
+	}
+	if v.IsValid() {
+		switch v.Kind() {
+		case reflect.Bool, reflect.Slice, reflect.Array, reflect.Map:
+		case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			if !v.IsNil() {
+				return "", false
+			}
+		default:
+			return "", false
+		}
+	}
+	var b buffer
+	newPyRepr(&b).write(v)
+	return string(b.contents), true
+}
+
+// hasCustomFormat returns true if fmt would use a method on the value to print it.
+func hasCustomFormat(v reflect.Value) bool {
+	if !v.IsValid() || !v.CanInterface() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+	}
+	switch v.Interface().(type) {
+	case fmt.Formatter, fmt.Stringer, error:
+		return true
+	}
+	return false
+}
+
+// pyRepr writes Python repr() style representations of values into a buffer.
+type pyRepr struct {
+	buf  *buffer
+	seen visited
+	// dataclass writes structs like Python dataclasses, e.g. Point(x=1, y=2), instead of using fmt.
+	dataclass bool
+	// omitZero skips zero-valued struct fields when writing dataclasses.
+	omitZero bool
+}
+
+func newPyRepr(b *buffer) *pyRepr {
+	return &pyRepr{buf: b, seen: visited{}}
+}
+
+// write writes the Python repr() of a value to the buffer. Containers are written recursively,
+// and a container that contains itself is written as [...] or {...}, like Python does.
+func (p *pyRepr) write(v reflect.Value) {
+	b := p.buf
+	if !v.IsValid() {
+		b.WriteString("None")
+		return
+	}
+	if hasCustomFormat(v) && !p.isDataclass(v) {
+		fmt.Fprint(b, v.Interface())
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("None")
+			return
+		}
+		p.write(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("None")
+			return
+		}
+		if !p.seen.enter(v) {
+			b.WriteString("...")
+			return
+		}
+		p.write(v.Elem())
+		p.seen.leave(v)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			b.WriteString("None")
+			return
+		}
+		fmt.Fprint(b, v)
+	case reflect.Bool:
+		if v.Bool() {
+			b.WriteString("True")
+		} else {
+			b.WriteString("False")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(pyFloatRepr(v.Float(), v.Type().Bits(), true))
+	case reflect.Complex64, reflect.Complex128:
+		b.WriteString(pyComplexRepr(v.Complex(), v.Type().Bits()/2))
+	case reflect.String:
+		b.WriteString(pyQuote(v.String(), false))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b.WriteString(pyQuote(string(v.Bytes()), true))
+			return
+		}
+		if v.Len() > 0 {
+			if !p.seen.enter(v) {
+				b.WriteString("[...]")
+				return
+			}
+			defer p.seen.leave(v)
+		}
+		p.writeList(v)
+	case reflect.Array:
+		p.writeList(v)
+	case reflect.Map:
+		if v.Len() > 0 {
+			if !p.seen.enter(v) {
+				b.WriteString("{...}")
+				return
+			}
+			defer p.seen.leave(v)
+		}
+		b.WriteString("{")
+		for i, key := range sortedKeys(v) {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			p.write(key)
+			b.WriteString(": ")
+			p.write(v.MapIndex(key))
+		}
+		b.WriteString("}")
+	case reflect.Struct:
+		if p.dataclass {
+			p.writeDataclass(v)
+			return
+		}
+		fmt.Fprint(b, v)
+	default:
+		fmt.Fprint(b, v)
+	}
+}
+
+// isDataclass returns true if v is a struct, or a pointer or interface holding one, that's written
+// as a dataclass. Those are written field by field even if they have their own String method.
+func (p *pyRepr) isDataclass(v reflect.Value) bool {
+	if !p.dataclass {
+		return false
+	}
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Struct
+}
+
+func (p *pyRepr) writeList(v reflect.Value) {
+	p.buf.WriteString("[")
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.write(v.Index(i))
+	}
+	p.buf.WriteString("]")
+}
+
+// sortedKeys returns the keys of a map in a stable order: numerically for numbers, lexically for
+// strings, and by their printed form for everything else.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessValue(keys[i], keys[j])
+	})
+	return keys
+}
+
+func lessValue(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	if a.Kind() == b.Kind() {
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// pyFloatRepr formats a float the way Python's repr() does: the shortest representation that
+// round-trips, in scientific notation only for very large or small exponents. If forceDot is set,
+// integral values get a trailing ".0".
+func pyFloatRepr(f float64, bits int, forceDot bool) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	s := strconv.FormatFloat(f, 'e', -1, bits)
+	_, exp := split(s, 'e')
+	if e, err := strconv.Atoi(exp); err == nil && (e < -4 || e >= 16) {
+		return s
+	}
+	s = strconv.FormatFloat(f, 'f', -1, bits)
+	if forceDot && !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// pyComplexRepr formats a complex number the way Python's repr() does, e.g. (1+2j) or 2j.
+func pyComplexRepr(c complex128, bits int) string {
+	im := pyFloatRepr(imag(c), bits, false) + "j"
+	if real(c) == 0 && !math.Signbit(real(c)) {
+		return im
+	}
+	if im[0] != '-' {
+		im = "+" + im
+	}
+	return "(" + pyFloatRepr(real(c), bits, false) + im + ")"
+}
+
+// pyQuote quotes a string the way Python's repr() does for str, or for bytes if isBytes is set.
+// Single quotes are used unless the string contains a single quote and no double quotes.
+func pyQuote(s string, isBytes bool) string {
+	quote := byte('\'')
+	if strings.Contains(s, "'") && !strings.Contains(s, `"`) {
+		quote = '"'
+	}
+	out := make([]byte, 0, len(s)+3)
+	if isBytes {
+		out = append(out, 'b')
+	}
+	out = append(out, quote)
+	for i := 0; i < len(s); {
+		c, size := rune(s[i]), 1
+		if !isBytes {
+			c, size = utf8.DecodeRuneInString(s[i:])
+		}
+		switch {
+		case c == utf8.RuneError && size == 1 && !isBytes:
+			out = append(out, fmt.Sprintf(`\x%02x`, s[i])...)
+		case c == rune(quote) || c == '\\':
+			out = append(out, '\\', byte(c))
+		case c == '\t':
+			out = append(out, `\t`...)
+		case c == '\n':
+			out = append(out, `\n`...)
+		case c == '\r':
+			out = append(out, `\r`...)
+		case c < ' ' || c == 0x7f || (isBytes && c > 0x7f):
+			out = append(out, fmt.Sprintf(`\x%02x`, c)...)
+		case c < 0x7f || unicode.IsPrint(c):
+			out = append(out, s[i:i+size]...)
+		case c <= 0xff:
+			out = append(out, fmt.Sprintf(`\x%02x`, c)...)
+		case c <= 0xffff:
+			out = append(out, fmt.Sprintf(`\u%04x`, c)...)
+		default:
+			out = append(out, fmt.Sprintf(`\U%08x`, c)...)
+		}
+		i += size
+	}
+	out = append(out, quote)
+	return string(out)
+}
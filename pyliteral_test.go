@@ -0,0 +1,79 @@
+package pyfmt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPyLiterals(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	var nilPtr *point
+	recursive := []interface{}{1, nil}
+	recursive[1] = recursive
+
+	py := &Formatter{PyLiterals: true}
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		// Bools
+		{"{}", true, "True"},
+		{"{}", false, "False"},
+		{"{:>6}", true, "  True"},
+		{"{:d}", true, "1"},
+		{"{:03d}", false, "000"},
+		{"{:.1f}", true, "1.0"},
+		{"{:.0%}", true, "100%"},
+
+		// None
+		{"{}", nil, "None"},
+		{"{}", nilPtr, "None"},
+		{"{:^8}", nil, "  None  "},
+
+		// Lists
+		{"{}", []string{"a", "b"}, "['a', 'b']"},
+		{"{}", [2]int{1, 2}, "[1, 2]"},
+		{"{}", []int{}, "[]"},
+		{"{}", []float64{1, 0.5, 1e20, math.Inf(-1)}, "[1.0, 0.5, 1e+20, -inf]"},
+		{"{}", []interface{}{nil, true, "it's", 2i, 1 - 2i}, `[None, True, "it's", 2j, (1-2j)]`},
+		{"{}", []string{"tab\there", "quote'\"", "é", "\x00"}, `['tab\there', 'quote\'"', 'é', '\x00']`},
+		{"{}", []byte("hi\n"), `b'hi\n'`},
+		{"{}", [][]int{{1}, {2, 3}}, "[[1], [2, 3]]"},
+		{"{}", []*point{nil, {1, 2}}, "[None, {1 2}]"},
+		{"{}", []stringer{1}, "[custom stringer]"},
+		{"{}", recursive, "[1, [...]]"},
+		{"{:.4}", []int{1, 2, 3}, "[1, "},
+
+		// Dicts
+		{"{}", map[string]int{"b": 2, "a": 1}, "{'a': 1, 'b': 2}"},
+		{"{}", map[int]bool{10: true, 9: false}, "{9: False, 10: True}"},
+		{"{}", map[string][]string{"k": {"v"}}, "{'k': ['v']}"},
+		{"{}", map[string]interface{}{}, "{}"},
+
+		// Other values are unchanged
+		{"{}", "abc", "abc"},
+		{"{}", 1.0, "1"},
+		{"{}", point{1, 2}, "{1 2}"},
+		{"{:r}", []string{"a"}, `[]string{"a"}`},
+		{"{}", stringer(3), "custom stringer"},
+	}
+
+	for _, test := range tests {
+		got, err := py.Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestPyLiteralsDefaultOff(t *testing.T) {
+	if got := Must("{} {} {}", true, []string{"a"}, map[string]int{"a": 1}); got != "true [a] map[a:1]" {
+		t.Error(Must("Must(...) = {}, Want: true [a] map[a:1]", got))
+	}
+}
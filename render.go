@@ -3,6 +3,7 @@ package pyfmt
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -14,10 +15,17 @@ type flags struct {
 	sign       string
 	showRadix  bool
 	minWidth   string
+	grouping   string
 	precision  string
 	renderVerb string
 	percent    bool
 	empty      bool
+	// twos renders negative integers as their two's complement, in twosBits bits, or the size of
+	// their type if twosBits is zero.
+	twos     bool
+	twosBits int
+	// printf is a printf verb, like "%q", to pass through to fmt instead of using the other flags.
+	printf string
 }
 
 // Render is the renderer used to render dispatched format strings into a buffer that's been set up
@@ -25,12 +33,14 @@ type flags struct {
 type render struct {
 	buf *buffer
 	val interface{}
+	cfg *Formatter
 
 	flags
 }
 
-func (r *render) init(buf *buffer) {
+func (r *render) init(buf *buffer, cfg *Formatter) {
 	r.buf = buf
+	r.cfg = cfg
 	r.clearFlags()
 }
 
@@ -45,14 +55,30 @@ const (
 	radixState
 	zeroState
 	widthState
+	groupingState
 	precisionState
 	verbState
 	endState
 )
 
-// validFlags are 'bdoxXeEfFgGrts%'
+// validFlags are 'bdoxXeEfFgGjprRSts%'
 func validFlag(b byte) bool {
-	return (b == 'b' || b == 'd' || b == 'o' || b == 'x' || b == 'X' || b == 'e' || b == 'E' || b == 'f' || b == 'F' || b == 'g' || b == 'G' || b == 'r' || b == 't' || b == 's' || b == '%')
+	return (b == 'b' || b == 'd' || b == 'o' || b == 'x' || b == 'X' || b == 'e' || b == 'E' || b == 'f' || b == 'F' || b == 'g' || b == 'G' || b == 'j' || b == 'p' || b == 'r' || b == 'R' || b == 'S' || b == 't' || b == 's' || b == '%')
+}
+
+// namedTypes are the format types that are spelled out as words instead of a single character.
+// They must come at the end of the format spec, like the single-character types.
+var namedTypes = []string{"base64", "base64url", "hexdump", "chain", "tree", "iec", "si", "eng",
+	"ordinal", "words"}
+
+// namedType returns true if the rest of a format spec is one of the named types.
+func namedType(s string) bool {
+	for _, name := range namedTypes {
+		if s == name {
+			return true
+		}
+	}
+	return false
 }
 
 func isDigit(d byte) bool {
@@ -60,7 +86,7 @@ func isDigit(d byte) bool {
 }
 
 // splitFlags splits out the flags into the various fields.
-func splitFlags(flags string) (align, sign, radix, zeroPad, minWidth, precision, verb string, err error) {
+func splitFlags(flags string) (align, sign, radix, zeroPad, minWidth, grouping, precision, verb string, err error) {
 	end := len(flags)
 	if end == 0 {
 		return
@@ -109,6 +135,12 @@ func splitFlags(flags string) (align, sign, radix, zeroPad, minWidth, precision,
 			}
 			minWidth = flags[i:j]
 			i = j
+			state = groupingState
+		case groupingState:
+			if flags[i] == ',' || flags[i] == '_' {
+				grouping = flags[i : i+1]
+				i++
+			}
 			state = precisionState
 		case precisionState:
 			if flags[i] == '.' {
@@ -125,7 +157,10 @@ func splitFlags(flags string) (align, sign, radix, zeroPad, minWidth, precision,
 			}
 			state = verbState
 		case verbState:
-			if validFlag(flags[i]) {
+			if namedType(flags[i:]) {
+				verb = flags[i:]
+				i = end
+			} else if validFlag(flags[i]) {
 				verb = flags[i : i+1]
 				i++
 			}
@@ -141,16 +176,59 @@ func splitFlags(flags string) (align, sign, radix, zeroPad, minWidth, precision,
 	return
 }
 
+// splitTwos splits the two's complement modifier, a '~' optionally followed by a number of bits,
+// off of the end of the flags.
+func splitTwos(flags string) (rest, bits string, twos bool) {
+	i := strings.LastIndexByte(flags, '~')
+	if i < 0 {
+		return flags, "", false
+	}
+	for j := i + 1; j < len(flags); j++ {
+		if !isDigit(flags[j]) {
+			return flags, "", false
+		}
+	}
+	return flags[:i], flags[i+1:], true
+}
+
 func (r *render) parseFlags(flags string) error {
 	r.renderVerb = "v"
 	if flags == "" {
 		r.empty = true
 		return nil
 	}
-	align, sign, radix, zeroPad, minWidth, precision, verb, err := splitFlags(flags)
+	if isPrintfSpec(flags) {
+		if err := parsePrintf(flags); err != nil {
+			return err
+		}
+		r.printf = flags
+		return nil
+	}
+	flags, twosBits, twos := splitTwos(flags)
+	align, sign, radix, zeroPad, minWidth, grouping, precision, verb, err := splitFlags(flags)
 	if err != nil {
 		return Error("Invalid flag pattern: {}, {}", flags, err)
 	}
+	isRadixVerb := verb == "b" || verb == "o" || verb == "x" || verb == "X"
+	if grouping == "," && isRadixVerb {
+		return Error("Cannot specify ',' with '{}'.", verb)
+	}
+	if grouping != "" && !groupingVerb(verb) {
+		return Error("Cannot specify '{}' with '{}'.", grouping, verb)
+	}
+	r.grouping = grouping
+	if twos {
+		if !isRadixVerb {
+			return Error("Two's complement '~' requires the b, o, x, or X type, got '{}'", verb)
+		}
+		r.twos = true
+		if twosBits != "" {
+			r.twosBits, err = strconv.Atoi(twosBits)
+			if err != nil || r.twosBits < 1 || r.twosBits > 64 {
+				return Error("Two's complement width must be between 1 and 64 bits, got {}", twosBits)
+			}
+		}
+	}
 	if len(align) > 1 {
 		var size int
 		r.fillChar, size = utf8.DecodeRuneInString(align)
@@ -205,6 +283,9 @@ func (r *render) parseFlags(flags string) error {
 			r.renderVerb = "f"
 		case "r":
 			r.renderVerb = "#v"
+		case "R", "p", "j", "base64", "base64url", "hexdump", "chain", "tree",
+			"S", "iec", "si", "eng", "ordinal", "words":
+			r.renderVerb = verb
 		case "t":
 			r.renderVerb = "T"
 		case "s":
@@ -222,6 +303,47 @@ func (r *render) render() error {
 	var prefix, radix string
 	var width int64
 	var err error
+	r.renderNil()
+	if r.printf != "" {
+		return r.renderPrintf()
+	}
+	r.derefScalar()
+	if err = r.unwrapValue(); err != nil {
+		return err
+	}
+	r.renderEnum()
+	if r.cfg.PyLiterals {
+		r.pyLiteral()
+	}
+	if r.renderVerb == "R" {
+		r.renderRepr()
+	}
+	switch r.renderVerb {
+	case "p":
+		return r.renderPretty()
+	case "j":
+		return r.renderJSON()
+	case "hexdump":
+		return r.renderHexdump()
+	case "tree":
+		return r.renderErrorChain()
+	case "base64", "base64url":
+		if err = r.renderBase64(); err != nil {
+			return err
+		}
+	case "chain":
+		if err = r.renderErrorChain(); err != nil {
+			return err
+		}
+	case "S", "iec", "si", "eng":
+		if err = r.renderUnits(); err != nil {
+			return err
+		}
+	case "ordinal", "words":
+		if err = r.renderSpelled(); err != nil {
+			return err
+		}
+	}
 	if r.empty {
 		fmt.Fprint(r.buf, r.val)
 		return nil
@@ -232,6 +354,11 @@ func (r *render) render() error {
 			return err
 		}
 	}
+	if r.twos {
+		if err = r.setupTwos(); err != nil {
+			return err
+		}
+	}
 	if r.showRadix {
 		if r.renderVerb == "x" || r.renderVerb == "X" {
 			radix = "#"
@@ -291,6 +418,13 @@ func (r *render) render() error {
 		}
 	}
 
+	if r.grouping != "" {
+		str, err = r.groupDigits(str, int(width))
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(str) > 0 {
 		if str[0] != '(' && (r.align == left || r.align == padSign) {
 			if str[0] == '-' {
@@ -372,3 +506,95 @@ func transformPercent(p string) (string, error) {
 	return p + "00%", nil
 
 }
+
+// setupTwos replaces a negative signed integer with its two's complement, as an unsigned integer of
+// the same size, or of the explicitly requested number of bits.
+func (r *render) setupTwos() error {
+	v := valueOf(r.val)
+	if !v.IsValid() || !isInteger(v.Kind()) {
+		return Error("Two's complement '~' requires an integer, got {:t}", r.val)
+	}
+	bits := uint(r.twosBits)
+	if bits == 0 {
+		bits = uint(v.Type().Bits())
+	}
+	max := ^uint64(0) >> (64 - bits)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if bits < 64 && (n < -(int64(1)<<(bits-1)) || (n > 0 && uint64(n) > max)) {
+			return Error("{} does not fit in {} bits", n, bits)
+		}
+		r.val = uint64(n) & max
+	default:
+		if v.Uint() > max {
+			return Error("{} does not fit in {} bits", v.Uint(), bits)
+		}
+	}
+	return nil
+}
+
+// groupingVerb returns true if the grouping option can be used with the type, which must be one of
+// the numeric types.
+func groupingVerb(verb string) bool {
+	switch verb {
+	case "", "d", "b", "o", "x", "X", "e", "E", "f", "F", "g", "G", "%":
+		return true
+	}
+	return false
+}
+
+// groupDigits inserts the grouping separator between the digits of the integer part of a formatted
+// number: every three digits for decimal numbers, and every four for the b, o, x, and X types. When
+// zero-padding, the number is padded with grouped zeros to the width, as Python does, e.g.
+// "00,001,234" rather than "000001,234".
+func (r *render) groupDigits(str string, width int) (string, error) {
+	if _, ok := numberOf(r.val); !ok {
+		return "", Error("Cannot specify '{}' with non-numeric value of type {:t}.", r.grouping, r.val)
+	}
+	size := 3
+	digit := isDigit
+	if r.renderVerb == "b" || r.renderVerb == "o" || r.renderVerb == "x" || r.renderVerb == "X" {
+		size = 4
+		digit = isHexDigit
+	}
+	start := 0
+	for start < len(str) && (str[start] == '-' || str[start] == '+' || str[start] == ' ') {
+		start++
+	}
+	if start+1 < len(str) && str[start] == '0' && strings.IndexByte("bBoOxX", str[start+1]) >= 0 {
+		start += 2
+	}
+	end := start
+	for end < len(str) && digit(str[end]) {
+		end++
+	}
+	digits := str[start:end]
+	if r.fillChar == '0' && r.align == padSign {
+		// Pad with enough zeros that the grouped digits fill the width, which may go one over the
+		// width to avoid starting with a separator.
+		avail := width - len(str) + len(digits)
+		n := len(digits)
+		for n+(n-1)/size < avail {
+			n++
+		}
+		digits = strings.Repeat("0", n-len(digits)) + digits
+	}
+	if len(digits) <= size {
+		return str, nil
+	}
+	grouped := make([]byte, 0, len(str)+len(digits)/size)
+	grouped = append(grouped, str[:start]...)
+	for i := 0; i < len(digits); i++ {
+		if i > 0 && (len(digits)-i)%size == 0 {
+			grouped = append(grouped, r.grouping...)
+		}
+		grouped = append(grouped, digits[i])
+	}
+	grouped = append(grouped, str[end:]...)
+	return string(grouped), nil
+}
+
+func isHexDigit(d byte) bool {
+	return isDigit(d) || (d >= 'a' && d <= 'f') || (d >= 'A' && d <= 'F')
+}
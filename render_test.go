@@ -7,16 +7,16 @@ import (
 	"testing"
 )
 
-const flagRegex = `\A((?:.[<>=^])|(?:[<>=^])?)([\+\- ]?)(#?)(0?)(\d*)(\.\d*)?([bdoxXeEfFgGrts%]?)\z`
+const flagRegex = `\A((?:.[<>=^])|(?:[<>=^])?)([\+\- ]?)(#?)(0?)(\d*)([,_]?)(\.\d*)?([bdoxXeEfFgGrts%]?)\z`
 
 func TestSplitFlags(t *testing.T) {
 	var flagPattern = regexp.MustCompile(flagRegex)
 
 	tests := []string{"", "4<", "+=", "^10.3", ":> #010.4X",
-		"<0%", "10.10E", "#x", "<<", "==", "💩<"}
+		"<0%", "10.10E", "#x", "<<", "==", "💩<", ",", "_x", "010,.2f"}
 
 	for _, test := range tests {
-		align, sign, radix, zeroPad, minWidth, precision, verb, err := splitFlags(test)
+		align, sign, radix, zeroPad, minWidth, grouping, precision, verb, err := splitFlags(test)
 
 		if err != nil {
 			t.Error(Must("splitFlags({}) errored: {}!", test, err))
@@ -26,7 +26,7 @@ func TestSplitFlags(t *testing.T) {
 			t.Error(Must("Could not match with regex!: {}", test))
 		}
 
-		got := []string{test, align, sign, radix, zeroPad, minWidth, precision, verb}
+		got := []string{test, align, sign, radix, zeroPad, minWidth, grouping, precision, verb}
 		want := flagPattern.FindStringSubmatch(test)
 		if !reflect.DeepEqual(got, want) {
 			t.Error(Must("splitFlags({}) = \n{:r} Want: \n{:r}", test, got, want))
@@ -35,9 +35,9 @@ func TestSplitFlags(t *testing.T) {
 }
 
 func TestSplitFlagsError(t *testing.T) {
-	tests := []string{"<><>", "asdf", "^^^", "^#xx", ":>  #010.4x"}
+	tests := []string{"<><>", "asdf", "^^^", "^#xx", ":>  #010.4x", ",,", "_,"}
 	for _, test := range tests {
-		_, _, _, _, _, _, _, err := splitFlags(test)
+		_, _, _, _, _, _, _, _, err := splitFlags(test)
 		if err == nil {
 			t.Error(Must("splitFlags({}) did not error!", test))
 		}
@@ -59,6 +59,9 @@ func TestParseFlags(t *testing.T) {
 		{"+.4o", flags{precision: ".4", sign: "+", renderVerb: "o"}},
 		{"r", flags{renderVerb: "#v"}},
 		{"#010X", flags{showRadix: true, align: padSign, fillChar: '0', minWidth: "10", renderVerb: "X"}},
+		{"10,.2f", flags{minWidth: "10", grouping: ",", precision: ".2", renderVerb: "f"}},
+		{"#x~", flags{showRadix: true, renderVerb: "x", twos: true}},
+		{"_X~12", flags{grouping: "_", renderVerb: "X", twos: true, twosBits: 12}},
 	}
 
 	for _, test := range tests {
@@ -86,6 +89,8 @@ func TestParseFlagsError(t *testing.T) {
 		{"asdf", "Invalid"},
 		{":::", "Invalid"},
 		{">10.10.", "Invalid"},
+		{"_s", "Cannot specify '_' with 's'."},
+		{",x", "Cannot specify ',' with 'x'."},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,73 @@
+package pyfmt
+
+import "strings"
+
+// Scope is a stack of maps and structs, searched in order for named arguments, like Python's
+// collections.ChainMap. The first map or struct with the name wins, so values in a child scope
+// override those in its parents, e.g. per-user values over tenant defaults over global constants.
+// Scopes are Args, and are passed to FmtArgs.
+type Scope struct {
+	name   string
+	value  interface{}
+	parent *Scope
+}
+
+// NewScope returns a Scope that searches the values in order, so the first value overrides the
+// rest.
+func NewScope(values ...interface{}) *Scope {
+	var s *Scope
+	for i := len(values) - 1; i >= 0; i-- {
+		s = s.Push(values[i])
+	}
+	return s
+}
+
+// Push returns a child scope, which searches the value before the scope's values. The scope itself
+// is unchanged, so pushing is cheap, and the same scope can have many children.
+func (s *Scope) Push(value interface{}) *Scope {
+	return s.PushNamed("", value)
+}
+
+// PushNamed is like Push, but names the value, for describing it in errors. Unnamed values are
+// described by their type.
+func (s *Scope) PushNamed(name string, value interface{}) *Scope {
+	return &Scope{name: name, value: value, parent: s}
+}
+
+// Len returns zero, as scopes only have named arguments.
+func (s *Scope) Len() int {
+	return 0
+}
+
+// Positional returns an error, as scopes only have named arguments.
+func (s *Scope) Positional(i int) (interface{}, error) {
+	return nil, Error("index out of bounds: {}", i)
+}
+
+// Named returns the value with the name from the first of the scope's values that has it. Errors
+// other than the name not being found, like an error returned by a method, are returned rather
+// than searching the rest of the values.
+func (s *Scope) Named(name string) (interface{}, error) {
+	return s.namedWith(std, name)
+}
+
+func (s *Scope) namedWith(fm *Formatter, name string) (interface{}, error) {
+	var searched []string
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.value == nil {
+			continue
+		}
+		val, err := fm.elementByName(name, true, scope.value)
+		if _, missing := err.(missingError); !missing {
+			// Only a missing name is looked up in the parent scopes, and other errors are
+			// returned as is, so that e.g. a failing method isn't hidden by a parent's value.
+			return val, err
+		}
+		if scope.name != "" {
+			searched = append(searched, scope.name)
+		} else {
+			searched = append(searched, Must("{:t}", scope.value))
+		}
+	}
+	return nil, missingError{Error("could not find {} in scopes: {}", name, strings.Join(searched, ", "))}
+}
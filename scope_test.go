@@ -0,0 +1,75 @@
+package pyfmt
+
+import "testing"
+
+func TestScope(t *testing.T) {
+	type tenant struct {
+		Name  string
+		Quota int `json:"quota"`
+	}
+	global := map[string]interface{}{"product": "Acme", "quota": 1, "support": "help@acme"}
+	defaults := NewScope(tenant{Name: "corp", Quota: 10}, global)
+	user := defaults.Push(map[string]interface{}{"user": "ann", "quota": 50})
+
+	tests := []struct {
+		fmtStr string
+		scope  *Scope
+		want   string
+	}{
+		{"{product}", NewScope(global), "Acme"},
+		{"{Name} {Quota} {support}", defaults, "corp 10 help@acme"},
+		{"{user} {Quota} {quota} {Name}", user, "ann 10 50 corp"},
+		{"{product:>6}", user.Push(map[string]string{"product": "Beta"}), "  Beta"},
+		{"{product}", user, "Acme"},
+		{"{product}", user.Push(nil), "Acme"},
+		{"{user.Name}", NewScope(map[string]tenant{"user": {Name: "x"}}), "x"},
+		{"{Name}", NewScope(&tenant{Name: "ptr"}), "ptr"},
+	}
+
+	for _, test := range tests {
+		got, err := FmtArgs(test.fmtStr, test.scope)
+		if err != nil {
+			t.Error(Must("FmtArgs({fmtStr}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("FmtArgs({fmtStr}) = {1}, Want: {want}", test, got))
+		}
+	}
+
+	json := &Formatter{JSONTags: true}
+	if got := json.MustArgs("{quota}", NewScope(tenant{Quota: 3}, global)); got != "3" {
+		t.Error(Must("MustArgs({{quota}}) = {}, Want: 3", got))
+	}
+	if got, err := user.Named("user"); err != nil || got != "ann" {
+		t.Error(Must("Named(user) = {}, {}, Want: ann", got, err))
+	}
+}
+
+func TestScopeError(t *testing.T) {
+	scope := NewScope(map[string]int{"a": 1}).PushNamed("user", map[string]int{"b": 2})
+
+	tests := []struct {
+		fmtStr  string
+		scope   *Scope
+		wantErr string
+	}{
+		{"{c}", scope, "could not find c in scopes: user, map[string]int"},
+		{"{}", scope, "too large offset: 0"},
+		{"{0}", scope, "index out of bounds: 0"},
+		{"{a}", NewScope(), "could not find a in scopes: "},
+		{"{a.b}", scope, "attempted to get item by name from non-struct, non-map: 1 int"},
+		// Only missing names fall through to the parent scopes.
+		{"{Email}", NewScope(brokenUser{"bob"}, map[string]string{"Email": "x"}), "error calling method Email: no email"},
+		{"{token}", NewScope(map[string]interface{}{"token": LazyErr(func() (interface{}, error) {
+			return nil, Error("expired")
+		})}, map[string]string{"token": "x"}), "error computing lazy value: expired"},
+		{"{ID}", NewScope(history{}, map[string]int{"ID": 1}), "ambiguous field name: ID"},
+	}
+
+	for _, test := range tests {
+		_, err := FmtArgs(test.fmtStr, test.scope)
+		if err == nil || err.Error() != test.wantErr {
+			t.Error(Must("FmtArgs({fmtStr}) error = {1}, Want: {wantErr}", test, err))
+		}
+	}
+}
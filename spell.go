@@ -0,0 +1,140 @@
+package pyfmt
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberSpeller is an interface for the rules used by the 'ordinal' and 'words' types to write
+// integers as words in a particular language. Set a Formatter's Speller to use a language other
+// than English.
+type NumberSpeller interface {
+	// Ordinal returns the number with its ordinal suffix, e.g. "22nd".
+	Ordinal(n int64) string
+	// Cardinal returns the number spelled out as words, e.g. "forty-two".
+	Cardinal(n int64) string
+}
+
+// English spells out numbers in American English, and is used when a Formatter has no Speller set.
+var English NumberSpeller = english{}
+
+type english struct{}
+
+// Ordinal returns the number with an English ordinal suffix: 1st, 2nd, 3rd, 4th, 11th, 21st.
+func (english) Ordinal(n int64) string {
+	abs := n % 100
+	if abs < 0 {
+		abs = -abs
+	}
+	suffix := "th"
+	if abs < 11 || abs > 13 {
+		switch abs % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.FormatInt(n, 10) + suffix
+}
+
+var (
+	englishOnes = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight",
+		"nine", "ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+		"seventeen", "eighteen", "nineteen"}
+	englishTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishScales = []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion"}
+)
+
+// Cardinal spells out the number in English words, e.g. "one hundred twenty-three".
+func (english) Cardinal(n int64) string {
+	if n == 0 {
+		return englishOnes[0]
+	}
+	var words []string
+	if n < 0 {
+		words = append(words, "minus")
+	}
+	// Work with the magnitude as a uint64, so that the smallest int64 can be negated.
+	abs := uint64(n)
+	if n < 0 {
+		abs = -abs
+	}
+	var groups []uint64
+	for ; abs > 0; abs /= 1000 {
+		groups = append(groups, abs%1000)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i] == 0 {
+			continue
+		}
+		words = append(words, englishHundreds(groups[i])...)
+		if englishScales[i] != "" {
+			words = append(words, englishScales[i])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// englishHundreds spells out a number less than a thousand.
+func englishHundreds(n uint64) []string {
+	var words []string
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n == 0:
+	case n < 20:
+		words = append(words, englishOnes[n])
+	case n%10 == 0:
+		words = append(words, englishTens[n/10])
+	default:
+		words = append(words, englishTens[n/10]+"-"+englishOnes[n%10])
+	}
+	return words
+}
+
+// intOf returns the value of any integer kind as an int64, or false if it's not an integer or
+// doesn't fit.
+func intOf(val interface{}) (int64, bool) {
+	v := valueOf(val)
+	if !v.IsValid() {
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() <= math.MaxInt64 {
+			return int64(v.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// renderSpelled replaces an integer with its ordinal or its spelled out form, so that it can then
+// be aligned like any other string.
+func (r *render) renderSpelled() error {
+	n, ok := intOf(r.val)
+	if !ok {
+		return Error("{} format requires an integer, got {:t}", r.renderVerb, r.val)
+	}
+	speller := r.cfg.Speller
+	if speller == nil {
+		speller = English
+	}
+	if r.renderVerb == "ordinal" {
+		r.val = speller.Ordinal(n)
+	} else {
+		r.val = speller.Cardinal(n)
+	}
+	r.renderVerb = "v"
+	r.sign = ""
+	r.showRadix = false
+	return nil
+}
@@ -0,0 +1,85 @@
+package pyfmt
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+// french is a partial speller, to test plugging in other languages.
+type french struct{}
+
+func (french) Ordinal(n int64) string {
+	if n == 1 {
+		return "1er"
+	}
+	return strconv.FormatInt(n, 10) + "e"
+}
+
+func (french) Cardinal(n int64) string {
+	return []string{"zéro", "un", "deux"}[n]
+}
+
+func TestSpelledFormat(t *testing.T) {
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{:ordinal}", 1, "1st"},
+		{"{:ordinal}", 2, "2nd"},
+		{"{:ordinal}", 3, "3rd"},
+		{"{:ordinal}", 4, "4th"},
+		{"{:ordinal}", 11, "11th"},
+		{"{:ordinal}", 12, "12th"},
+		{"{:ordinal}", 13, "13th"},
+		{"{:ordinal}", 21, "21st"},
+		{"{:ordinal}", 22, "22nd"},
+		{"{:ordinal}", 113, "113th"},
+		{"{:ordinal}", 0, "0th"},
+		{"{:ordinal}", -1, "-1st"},
+		{"{:ordinal}", uint8(102), "102nd"},
+		{"{:>6ordinal}", 22, "  22nd"},
+		{"{n:ordinal} place", map[string]int{"n": 1}, "1st place"},
+
+		{"{:words}", 0, "zero"},
+		{"{:words}", 7, "seven"},
+		{"{:words}", 15, "fifteen"},
+		{"{:words}", 40, "forty"},
+		{"{:words}", 42, "forty-two"},
+		{"{:words}", 100, "one hundred"},
+		{"{:words}", 123, "one hundred twenty-three"},
+		{"{:words}", 1000001, "one million one"},
+		{"{:words}", 2019, "two thousand nineteen"},
+		{"{:words}", -42, "minus forty-two"},
+		{"{:words}", int64(math.MinInt64), "minus nine quintillion two hundred twenty-three quadrillion " +
+			"three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million " +
+			"seven hundred seventy-five thousand eight hundred eight"},
+		{"{:-^9words}", 3, "--three--"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestSpelledFormatSpeller(t *testing.T) {
+	f := &Formatter{Speller: french{}}
+	if got := f.Must("{:ordinal} {:ordinal} {:words}", 1, 2, 2); got != "1er 2e deux" {
+		t.Error(Must("Must() = {}, Want: 1er 2e deux", got))
+	}
+}
+
+func TestSpelledFormatError(t *testing.T) {
+	for _, param := range []interface{}{1.5, "1", uint64(math.MaxUint64)} {
+		if _, err := Fmt("{:words}", param); err == nil {
+			t.Error(Must("Fmt({{:words}}, {:r}) did not error when expected!", param))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package pyfmt
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultDelimiter introduces a placeholder in a Template.
+	defaultDelimiter = "$"
+	// defaultIDPattern matches the name of a placeholder without braces, e.g. "$user".
+	defaultIDPattern = `[_a-zA-Z][_a-zA-Z0-9]*`
+	// defaultBracedIDPattern matches the name of a placeholder in braces, which may be a compound
+	// field name, e.g. "${user.Name}" or "${users[0]}".
+	defaultBracedIDPattern = `[_a-zA-Z][_a-zA-Z0-9]*(?:\.[_a-zA-Z0-9]+|\[[^\[\]]*\])*`
+)
+
+// Template is the equivalent of Python's string.Template, which substitutes "$name" and "${name}"
+// placeholders, and writes "$$" as a single "$". Names are looked up in the arguments the same way
+// as field names in Fmt, so a single map or struct is usually passed, and placeholders in braces
+// may be compound field names like "${user.Name}". Values are formatted as if by "{}".
+//
+// The options may be changed before the Template is first used, but not after.
+type Template struct {
+	// Template is the template string.
+	Template string
+
+	// Delimiter introduces a placeholder. If empty, "$" is used.
+	Delimiter string
+
+	// IDPattern is a regular expression matching the names of placeholders without braces. If
+	// empty, names are an ASCII letter or underscore followed by letters, digits and underscores.
+	IDPattern string
+
+	// BracedIDPattern is a regular expression matching the names of placeholders in braces. If
+	// empty, names are as for IDPattern, followed by any number of ".field" or "[key]" subfields.
+	BracedIDPattern string
+
+	// Formatter formats the substituted values. If nil, the package-level defaults are used.
+	Formatter *Formatter
+
+	once    sync.Once
+	id      *regexp.Regexp
+	braced  *regexp.Regexp
+	initErr error
+}
+
+// Substitute substitutes each placeholder in the template with the value it names, as Python's
+// string.Template(template).substitute() does. Errors if a placeholder can't be found, or is
+// malformed.
+func Substitute(template string, a ...interface{}) (string, error) {
+	t := &Template{Template: template}
+	return t.Substitute(a...)
+}
+
+// SafeSubstitute is like Substitute, but leaves placeholders that can't be found, or are
+// malformed, in place, as Python's string.Template(template).safe_substitute() does.
+func SafeSubstitute(template string, a ...interface{}) (string, error) {
+	t := &Template{Template: template}
+	return t.SafeSubstitute(a...)
+}
+
+// Substitute is like the package-level Substitute, but uses the Template's options.
+func (t *Template) Substitute(a ...interface{}) (string, error) {
+	return t.substitute(false, a)
+}
+
+// SafeSubstitute is like the package-level SafeSubstitute, but uses the Template's options.
+func (t *Template) SafeSubstitute(a ...interface{}) (string, error) {
+	return t.substitute(true, a)
+}
+
+// init compiles the Template's patterns, anchored so that they only match at the start of the
+// text following the delimiter.
+func (t *Template) init() {
+	id, braced := t.IDPattern, t.BracedIDPattern
+	if id == "" {
+		id = defaultIDPattern
+	}
+	if braced == "" {
+		braced = defaultBracedIDPattern
+	}
+	if t.id, t.initErr = regexp.Compile(`^(?:` + id + `)`); t.initErr != nil {
+		return
+	}
+	t.braced, t.initErr = regexp.Compile(`^(?:` + braced + `)\}`)
+}
+
+// substitute does the work of Substitute, and of SafeSubstitute if safe is set.
+func (t *Template) substitute(safe bool, args []interface{}) (string, error) {
+	t.once.Do(t.init)
+	if t.initErr != nil {
+		return "", Error("invalid template pattern: {}", t.initErr)
+	}
+	delim := t.Delimiter
+	if delim == "" {
+		delim = defaultDelimiter
+	}
+	cfg := t.Formatter
+	if cfg == nil {
+		cfg = std
+	}
+
+	s := t.Template
+	var buf buffer
+	values := &valueArgs{args}
+	var lazy lazyCache
+	for {
+		i := strings.Index(s, delim)
+		if i < 0 {
+			buf.WriteString(s)
+			break
+		}
+		buf.WriteString(s[:i])
+		placeholder := s[i:]
+		s = s[i+len(delim):]
+
+		var name string
+		if strings.HasPrefix(s, delim) {
+			buf.WriteString(delim)
+			s = s[len(delim):]
+			continue
+		} else if strings.HasPrefix(s, "{") {
+			if m := t.braced.FindString(s[1:]); m != "" {
+				name = m[:len(m)-1]
+				s = s[len(m)+1:]
+			}
+		} else if m := t.id.FindString(s); m != "" {
+			name = m
+			s = s[len(m):]
+		}
+
+		if name == "" {
+			if safe {
+				buf.WriteString(delim)
+				continue
+			}
+			line, col := position(t.Template, len(t.Template)-len(placeholder))
+			return "", Error("invalid placeholder in string: line {}, col {}", line, col)
+		}
+		val, err := cfg.lookupArg(name, 0, values, &lazy)
+		if err != nil {
+			if safe {
+				buf.WriteString(placeholder[:len(placeholder)-len(s)])
+				continue
+			}
+			return "", Error("could not substitute {}: {}", name, err)
+		}
+		str, err := cfg.formatValue(val)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(str)
+	}
+	return string(buf.contents), nil
+}
+
+// position returns the 1-based line and column of a byte offset in a string.
+func position(s string, offset int) (int, int) {
+	line := strings.Count(s[:offset], "\n") + 1
+	col := offset - strings.LastIndex(s[:offset], "\n")
+	return line, col
+}
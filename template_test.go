@@ -0,0 +1,131 @@
+package pyfmt
+
+import "testing"
+
+func TestSubstitute(t *testing.T) {
+	type user struct {
+		Name  string
+		Quota int
+	}
+	vars := map[string]interface{}{
+		"user":  user{"ann", 5},
+		"quota": 10,
+		"names": []string{"a", "b"},
+	}
+
+	tests := []struct {
+		tmpl   string
+		params []interface{}
+		want   string
+	}{
+		{"", []interface{}{vars}, ""},
+		{"no placeholders", []interface{}{vars}, "no placeholders"},
+		{"$quota", []interface{}{vars}, "10"},
+		{"${quota}GB", []interface{}{vars}, "10GB"},
+		{"$user.Name exceeded ${quota}GB", []interface{}{vars}, "{ann 5}.Name exceeded 10GB"},
+		{"${user.Name} exceeded ${user.Quota}GB", []interface{}{vars}, "ann exceeded 5GB"},
+		{"${names[1]}", []interface{}{vars}, "b"},
+		{"$$quota costs $$5", []interface{}{vars}, "$quota costs $5"},
+		{"$Name has $Quota", []interface{}{user{"bob", 3}}, "bob has 3"},
+		{"$_x1$_x1", []interface{}{map[string]int{"_x1": 7}}, "77"},
+	}
+
+	for _, test := range tests {
+		got, err := Substitute(test.tmpl, test.params...)
+		if err != nil {
+			t.Error(Must("Substitute({tmpl}, {params}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Substitute({tmpl}, {params}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestSafeSubstitute(t *testing.T) {
+	vars := map[string]int{"a": 1, "b": 2}
+
+	tests := []struct {
+		tmpl   string
+		params []interface{}
+		want   string
+	}{
+		{"$a $b", []interface{}{vars}, "1 2"},
+		{"$a $c", []interface{}{vars}, "1 $c"},
+		{"${a} ${c} ${c.d}", []interface{}{vars}, "1 ${c} ${c.d}"},
+		{"$ $5 ${ ${a", []interface{}{vars}, "$ $5 ${ ${a"},
+		{"cost: $", []interface{}{vars}, "cost: $"},
+		{"$$c", []interface{}{vars}, "$c"},
+		{"$a", []interface{}{}, "$a"},
+	}
+
+	for _, test := range tests {
+		got, err := SafeSubstitute(test.tmpl, test.params...)
+		if err != nil {
+			t.Error(Must("SafeSubstitute({tmpl}, {params}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("SafeSubstitute({tmpl}, {params}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestSubstituteError(t *testing.T) {
+	vars := map[string]int{"a": 1}
+
+	tests := []struct {
+		tmpl   string
+		params []interface{}
+	}{
+		{"$b", []interface{}{vars}},
+		{"${b}", []interface{}{vars}},
+		{"${a", []interface{}{vars}},
+		{"${}", []interface{}{vars}},
+		{"$", []interface{}{vars}},
+		{"$1", []interface{}{vars}},
+		{"$a", []interface{}{}},
+		{"$ä", []interface{}{map[string]int{"ä": 7}}},
+	}
+
+	for _, test := range tests {
+		_, err := Substitute(test.tmpl, test.params...)
+		if err == nil {
+			t.Error(Must("Substitute({tmpl}, {params}) did not error when expected!", test))
+		}
+	}
+
+	_, err := Substitute("a\nbc $!", vars)
+	if err == nil || err.Error() != "invalid placeholder in string: line 2, col 4" {
+		t.Error(Must("Substitute() error = {}, Want: invalid placeholder in string: line 2, col 4", err))
+	}
+}
+
+func TestTemplateOptions(t *testing.T) {
+	vars := map[string]interface{}{"user": "ann", "user-id": 7, "ok": true}
+
+	tests := []struct {
+		tmpl *Template
+		want string
+	}{
+		{&Template{Template: "%user %% %{user}", Delimiter: "%"}, "ann % ann"},
+		{&Template{Template: "<<user! <<<<", Delimiter: "<<"}, "ann! <<"},
+		{&Template{Template: "$user-id", IDPattern: `[a-z]+(?:-[a-z]+)*`}, "7"},
+		{&Template{Template: "${user-id} $user", BracedIDPattern: `[a-z-]+`}, "7 ann"},
+		{&Template{Template: "$ok", Formatter: &Formatter{PyLiterals: true}}, "True"},
+		{&Template{Template: "$user-$ok", Formatter: &Formatter{LeftDelim: "<<", RightDelim: ">>"}}, "ann-true"},
+	}
+
+	for _, test := range tests {
+		got, err := test.tmpl.Substitute(vars)
+		if err != nil {
+			t.Error(Must("{0.Template}.Substitute() Errored: {1}", test.tmpl, err))
+		}
+		if got != test.want {
+			t.Error(Must("{0.Template}.Substitute() = {1}, Want: {2}", test.tmpl, got, test.want))
+		}
+	}
+
+	tmpl := &Template{Template: "$a", IDPattern: "("}
+	if _, err := tmpl.Substitute(vars); err == nil {
+		t.Error("Substitute() with an invalid IDPattern did not error when expected!")
+	}
+}
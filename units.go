@@ -0,0 +1,178 @@
+package pyfmt
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// iecUnits are the units of the 'iec' type, each 1024 times the last.
+	iecUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB", "ZiB", "YiB"}
+	// siByteUnits are the units of the 'si' type, each 1000 times the last.
+	siByteUnits = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB", "ZB", "YB"}
+	// siPrefixes are the prefixes of the 'S' type, each 1000 times the last, starting from 1e-24.
+	siPrefixes = []string{"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+)
+
+// siUnity is the index of the empty prefix in siPrefixes.
+const siUnity = 8
+
+// numberOf returns the value of any integer, float, or math/big number as a float64, or false if
+// the value isn't a number.
+func numberOf(val interface{}) (float64, bool) {
+	switch n := val.(type) {
+	case *big.Int:
+		if n != nil {
+			f, _ := new(big.Float).SetInt(n).Float64()
+			return f, true
+		}
+		return 0, false
+	case big.Int:
+		f, _ := new(big.Float).SetInt(&n).Float64()
+		return f, true
+	case *big.Float:
+		if n != nil {
+			f, _ := n.Float64()
+			return f, true
+		}
+		return 0, false
+	case *big.Rat:
+		if n != nil {
+			f, _ := n.Float64()
+			return f, true
+		}
+		return 0, false
+	}
+	v := valueOf(val)
+	if !v.IsValid() {
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// renderUnits replaces a number with a string scaled to a unit or prefix by the 'iec', 'si', 'S',
+// and 'eng' types, so that it can then be padded and aligned like any other string.
+func (r *render) renderUnits() error {
+	n, ok := numberOf(r.val)
+	if !ok {
+		return Error("{} format requires a number, got {:t}", r.renderVerb, r.val)
+	}
+	prec := -1
+	if r.precision != "" {
+		p, err := strconv.Atoi(r.precision[1:])
+		if err != nil {
+			return Error("Can't convert precision {} to int", r.precision)
+		}
+		prec = p
+	}
+
+	var sign string
+	if n < 0 || (n == 0 && math.Signbit(n)) {
+		sign = "-"
+		n = -n
+	} else if r.sign == "+" || r.sign == " " {
+		sign = r.sign
+	}
+
+	var str string
+	switch {
+	case math.IsNaN(n):
+		str = "NaN"
+	case math.IsInf(n, 0):
+		str = "Inf"
+	case r.renderVerb == "iec":
+		str = scaleUnits(n, 1024, prec, iecUnits, 0)
+	case r.renderVerb == "si":
+		str = scaleUnits(n, 1000, prec, siByteUnits, 0)
+	case r.renderVerb == "S":
+		str = scaleUnits(n, 1000, prec, siPrefixes, siUnity)
+	case r.renderVerb == "eng":
+		str = engineering(n, prec)
+	}
+
+	r.val = sign + str
+	r.renderVerb = "v"
+	r.sign = ""
+	r.precision = ""
+	r.showRadix = false
+	return nil
+}
+
+// scaleUnits divides a non-negative number by the base until it's less than the base, and returns
+// it followed by a space and the matching unit. The units start at units[unity] for numbers between 1 and the
+// base, and units before unity are used for numbers less than one. With a negative precision,
+// at most two decimal places are shown, with trailing zeros removed.
+func scaleUnits(n, base float64, prec int, units []string, unity int) string {
+	i := unity
+	if n != 0 {
+		for n >= base && i < len(units)-1 {
+			n /= base
+			i++
+		}
+		for n < 1 && i > 0 {
+			n *= base
+			i--
+		}
+	}
+	str := formatScaled(n, prec)
+	// Rounding may have carried the number up to the base, e.g. 999.96 to "1000.0".
+	if f, err := strconv.ParseFloat(str, 64); err == nil && f >= base && i < len(units)-1 {
+		str = formatScaled(n/base, prec)
+		i++
+	}
+	return str + " " + units[i]
+}
+
+// formatScaled formats a scaled number with the given precision, or with at most two decimal
+// places if the precision is negative.
+func formatScaled(n float64, prec int) string {
+	if prec >= 0 {
+		return strconv.FormatFloat(n, 'f', prec, 64)
+	}
+	str := strconv.FormatFloat(n, 'f', 2, 64)
+	str = strings.TrimRight(str, "0")
+	return strings.TrimSuffix(str, ".")
+}
+
+// engineering formats a non-negative number in engineering notation: like scientific notation, but
+// with an exponent that's a multiple of three, so the mantissa is between 1 and 1000. The precision
+// defaults to six, like the 'e' type.
+func engineering(n float64, prec int) string {
+	if prec < 0 {
+		prec = 6
+	}
+	exp := 0
+	if n != 0 {
+		exp = int(math.Floor(math.Log10(n)/3)) * 3
+	}
+	mantissa := strconv.FormatFloat(n/math.Pow(10, float64(exp)), 'f', prec, 64)
+	// Rounding, or the inexact logarithm, may have left the mantissa outside of [1, 1000).
+	if f, _ := strconv.ParseFloat(mantissa, 64); f >= 1000 {
+		exp += 3
+		mantissa = strconv.FormatFloat(n/math.Pow(10, float64(exp)), 'f', prec, 64)
+	} else if f < 1 && n != 0 {
+		exp -= 3
+		mantissa = strconv.FormatFloat(n/math.Pow(10, float64(exp)), 'f', prec, 64)
+	}
+	expSign := "+"
+	if exp < 0 {
+		expSign = "-"
+		exp = -exp
+	}
+	expStr := strconv.Itoa(exp)
+	if len(expStr) < 2 {
+		expStr = "0" + expStr
+	}
+	return mantissa + "e" + expSign + expStr
+}
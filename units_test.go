@@ -0,0 +1,79 @@
+package pyfmt
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestUnitsFormat(t *testing.T) {
+	huge, _ := new(big.Int).SetString("5000000000000000000000000", 10)
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		// IEC binary units
+		{"{:iec}", 0, "0 B"},
+		{"{:iec}", 512, "512 B"},
+		{"{:iec}", 1024, "1 KiB"},
+		{"{:iec}", 1536, "1.5 KiB"},
+		{"{:.1iec}", uint64(1610612736), "1.5 GiB"},
+		{"{:.3iec}", int8(100), "100.000 B"},
+		{"{:.1iec}", 1048575, "1.0 MiB"},
+		{"{:iec}", 1.5 * 1024 * 1024, "1.5 MiB"},
+		{"{:>10.1iec}", 2048, "   2.0 KiB"},
+		{"{:<10iec}", 2048, "2 KiB     "},
+		{"{:*^11iec}", 2048, "***2 KiB***"},
+		{"{:+iec}", 2048, "+2 KiB"},
+		{"{:iec}", -2048, "-2 KiB"},
+		{"{:=10iec}", -2048, "-    2 KiB"},
+		{"{:iec}", huge, "4.14 YiB"},
+
+		// SI decimal units
+		{"{:si}", 999, "999 B"},
+		{"{:si}", 1000, "1 kB"},
+		{"{:.2si}", 1234567, "1.23 MB"},
+		{"{:.1si}", 999960, "1.0 MB"},
+		{"{:si}", *big.NewInt(3000000000), "3 GB"},
+
+		// SI prefixes
+		{"{:.3S}Hz", 2.4e9, "2.400 GHz"},
+		{"{:S}Hz", 500, "500 Hz"},
+		{"{:S}s", 0.0012, "1.2 ms"},
+		{"{:.1S}F", 4.7e-6, "4.7 µF"},
+		{"{:S}", 0, "0 "},
+		{"{: S}", 1e3, " 1 k"},
+		{"{:S}", math.Inf(-1), "-Inf"},
+		{"{:S}", math.NaN(), "NaN"},
+		{"{:S}", big.NewFloat(1.5e6), "1.5 M"},
+
+		// Engineering notation
+		{"{:eng}", 12300, "12.300000e+03"},
+		{"{:.2eng}", 0.00456, "4.56e-03"},
+		{"{:.1eng}", 1.5e-7, "150.0e-09"},
+		{"{:.0eng}", 999999, "1e+06"},
+		{"{:.3eng}", 0, "0.000e+00"},
+		{"{:+.2eng}", 1234, "+1.23e+03"},
+		{"{:>12.2eng}", -1234, "   -1.23e+03"},
+		{"{:.2eng}", big.NewInt(-1000), "-1.00e+03"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1:r}, Want: {want:r}", test, got))
+		}
+	}
+}
+
+func TestUnitsFormatError(t *testing.T) {
+	for _, spec := range []string{"{:iec}", "{:si}", "{:S}", "{:eng}"} {
+		if _, err := Fmt(spec, "12"); err == nil {
+			t.Error(Must("Fmt({}, \"12\") did not error when expected!", spec))
+		}
+	}
+}
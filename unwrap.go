@@ -0,0 +1,129 @@
+package pyfmt
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// defaultNilPlaceholder is printed for nil values in nil-safe mode, if the Formatter has no
+// NilPlaceholder.
+const defaultNilPlaceholder = "<nil>"
+
+// nilPath stands in for a value that couldn't be reached because the field name's path went
+// through a nil pointer, map, interface, or slice, when the Formatter is nil-safe.
+type nilPath struct{}
+
+// isNil returns true if the value is nil, or a nil pointer, map, interface, or slice, so that a
+// field name can't be followed through it.
+func isNil(val interface{}) bool {
+	if _, ok := val.(nilPath); ok || val == nil {
+		return true
+	}
+	v := valueOf(val)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface, reflect.Slice:
+		return v.IsNil()
+	}
+	return !v.IsValid()
+}
+
+// renderNil replaces values that couldn't be reached because of a nil in their path, and nil
+// values and pointers, with the Formatter's NilPlaceholder in nil-safe mode. The placeholder is
+// printed as a string, whatever the type. The 'r' and 't' types still describe nil values.
+func (r *render) renderNil() {
+	if !r.cfg.NilSafe {
+		return
+	}
+	if _, ok := r.val.(nilPath); !ok {
+		if r.renderVerb == "#v" || r.renderVerb == "T" || r.printf != "" || !(r.val == nil || isNilPointer(r.val)) {
+			return
+		}
+	}
+	r.setText(r.cfg.nilPlaceholder())
+}
+
+// setText replaces the value being rendered with text that's printed as a string, whatever the
+// type, clearing the flags that only apply to other types. The text is still aligned and padded.
+func (r *render) setText(text string) {
+	r.val = text
+	r.renderVerb = "v"
+	r.printf = ""
+	r.sign = ""
+	r.showRadix = false
+	r.grouping = ""
+	r.precision = ""
+	r.percent = false
+	r.twos = false
+}
+
+// nilPlaceholder returns the text printed for nil values in nil-safe mode.
+func (fm *Formatter) nilPlaceholder() string {
+	if fm.NilPlaceholder == "" {
+		return defaultNilPlaceholder
+	}
+	return fm.NilPlaceholder
+}
+
+// derefScalar replaces a pointer to a bool, number, or string with the value it points to, so
+// that the format spec applies to the value rather than the address. Pointers with their own
+// formatting methods are left alone, as are nil pointers and the 'r' and 't' types.
+func (r *render) derefScalar() {
+	if r.renderVerb == "#v" || r.renderVerb == "T" || r.printf != "" {
+		return
+	}
+	v := reflect.ValueOf(r.val)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+	switch r.val.(type) {
+	case fmt.Formatter, fmt.Stringer, error, encoding.TextMarshaler, driver.Valuer:
+		return
+	}
+	switch v.Elem().Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		r.val = v.Elem().Interface()
+	}
+}
+
+// unwrapValue replaces values implementing driver.Valuer with the value they hold, and values
+// implementing encoding.TextMarshaler with their text, for the interfaces the Formatter has opted
+// in to. Valuers are unwrapped first, so the numeric types apply to the numbers they hold. Neither
+// applies to the 'r' and 't' types, which describe the Go value itself.
+func (r *render) unwrapValue() error {
+	if r.renderVerb == "#v" || r.renderVerb == "T" {
+		return nil
+	}
+	if r.cfg.Valuers {
+		if valuer, ok := r.val.(driver.Valuer); ok && !isNilPointer(r.val) {
+			val, err := valuer.Value()
+			if err != nil {
+				return Error("error getting value of {:t}: {}", r.val, err)
+			}
+			r.val = val
+			if val == nil && r.cfg.NullText != "" {
+				r.setText(r.cfg.NullText)
+			}
+		}
+	}
+	if r.cfg.TextMarshalers && (r.renderVerb == "v" || r.renderVerb == "+v") {
+		if marshaler, ok := r.val.(encoding.TextMarshaler); ok && !isNilPointer(r.val) {
+			text, err := marshaler.MarshalText()
+			if err != nil {
+				return Error("error marshaling {:t} to text: {}", r.val, err)
+			}
+			r.val = string(text)
+		}
+	}
+	return nil
+}
+
+// isNilPointer returns true if the value is a nil pointer, which would likely panic if one of its
+// methods were called.
+func isNilPointer(val interface{}) bool {
+	v := reflect.ValueOf(val)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,202 @@
+package pyfmt
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type textID struct {
+	hex   string
+	valid bool
+}
+
+func (id textID) MarshalText() ([]byte, error) {
+	if !id.valid {
+		return nil, errors.New("invalid id")
+	}
+	return []byte(id.hex), nil
+}
+
+type textEnum int
+
+func (e textEnum) MarshalText() ([]byte, error) {
+	return []byte("enum-text"), nil
+}
+
+func (e textEnum) String() string {
+	return "enum-string"
+}
+
+type badValuer struct{}
+
+func (badValuer) Value() (driver.Value, error) {
+	return nil, errors.New("no value")
+}
+
+func TestUnwrapValues(t *testing.T) {
+	f := &Formatter{Valuers: true, TextMarshalers: true}
+	null := &Formatter{Valuers: true, NullText: "NULL"}
+	var nilID *textID
+
+	tests := []struct {
+		formatter *Formatter
+		fmtStr    string
+		param     interface{}
+		want      string
+	}{
+		// Off by default.
+		{std, "{}", textID{"ab12", true}, "{ab12 true}"},
+		{std, "{}", sql.NullInt64{Int64: 3, Valid: true}, "{3 true}"},
+		{std, "{}", textEnum(1), "enum-string"},
+
+		{f, "{}", textID{"ab12", true}, "ab12"},
+		{f, "{:>6}", textID{"ab12", true}, "  ab12"},
+		{f, "{:s}", textID{"ab12", true}, "ab12"},
+		{f, "{:t}", textID{"ab12", true}, "pyfmt.textID"},
+		{f, "{}", textEnum(1), "enum-text"},
+		{f, "{:d}", textEnum(1), "1"},
+		{f, "{}", nilID, "<nil>"},
+		{f, "{}", sql.NullString{String: "name", Valid: true}, "name"},
+		{f, "{:05d}", sql.NullInt64{Int64: 42, Valid: true}, "00042"},
+		{f, "{:.1%}", sql.NullFloat64{Float64: 0.5, Valid: true}, "50.0%"},
+		{f, "{:r}", sql.NullInt64{Int64: 42, Valid: true}, "sql.NullInt64{Int64:42, Valid:true}"},
+		{f, "{}", sql.NullInt64{}, "<nil>"},
+		{null, "{:>6d}", sql.NullInt64{}, "  NULL"},
+		{null, "{:.2f}", sql.NullFloat64{}, "NULL"},
+		{null, "{:.0%}", sql.NullFloat64{}, "NULL"},
+		{null, "{:>8,d}", sql.NullInt64{}, "    NULL"},
+		{null, "{:+#x~}", sql.NullInt64{}, "NULL"},
+		{null, "{:_x}", sql.NullInt64{}, "NULL"},
+		{null, "{:#x}", sql.NullInt64{Int64: 255, Valid: true}, "0xff"},
+		{null, "{v}", map[string]interface{}{"v": sql.NullBool{}}, "NULL"},
+		{&Formatter{Valuers: true, PyLiterals: true}, "{}", sql.NullString{}, "None"},
+	}
+
+	for _, test := range tests {
+		got, err := test.formatter.Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+}
+
+func TestUnwrapValuesError(t *testing.T) {
+	f := &Formatter{Valuers: true, TextMarshalers: true}
+	for _, param := range []interface{}{textID{}, badValuer{}} {
+		if _, err := f.Fmt("{}", param); err == nil {
+			t.Error(Must("Fmt({{}}, {:r}) did not error when expected!", param))
+		}
+	}
+}
+
+type profile struct {
+	Name string
+}
+
+type user struct {
+	Profile *profile
+	Tags    map[string]string
+	Groups  []string
+	Extra   interface{}
+}
+
+type request struct {
+	User *user
+	*profile
+}
+
+func TestNilSafe(t *testing.T) {
+	safe := &Formatter{NilSafe: true}
+	none := &Formatter{NilSafe: true, NilPlaceholder: "None"}
+	n := 5
+	req := request{User: &user{}}
+
+	tests := []struct {
+		formatter *Formatter
+		fmtStr    string
+		param     interface{}
+		want      string
+	}{
+		{safe, "{User.Profile.Name}", req, "<nil>"},
+		{safe, "{User.Tags[a]}", req, "<nil>"},
+		{safe, "{User.Groups[0]}", req, "<nil>"},
+		{safe, "{User.Extra.Name}", req, "<nil>"},
+		{safe, "{Name}", req, "<nil>"},
+		{safe, "{User.Profile.Name}", request{}, "<nil>"},
+		{safe, "{User.Profile}", req, "<nil>"},
+		{safe, "{User.Profile.Name}", request{User: &user{Profile: &profile{"ann"}}}, "ann"},
+		{none, "{User.Profile.Name:>6}", req, "  None"},
+		{none, "{User.Profile.Name:.2f}", req, "None"},
+		{none, "{User.Profile.Name:+#_x}", req, "None"},
+		{none, "{User.Profile.Name:%d}", req, "None"},
+		{none, "{0}", nil, "None"},
+		{none, "{0:t}", (*int)(nil), "*int"},
+		{none, "{0:r}", (*int)(nil), "(*int)(nil)"},
+		{none, "{:d}", &n, "5"},
+	}
+
+	for _, test := range tests {
+		got, err := test.formatter.Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+
+	if got := none.PercentMust("%(User.Profile.Name)5d", req); got != " None" {
+		t.Error(Must("PercentMust() = {}, Want:  None", got))
+	}
+	if got, _ := (&Template{Template: "${User.Profile.Name}", Formatter: none}).Substitute(req); got != "None" {
+		t.Error(Must("Substitute() = {}, Want: None", got))
+	}
+	for _, fmtStr := range []string{"{User.Profile.Name}", "{Name}"} {
+		if _, err := Fmt(fmtStr, req); err == nil {
+			t.Error(Must("Fmt({}) did not error when expected without NilSafe!", fmtStr))
+		}
+	}
+}
+
+type ptrStringer int
+
+func (p *ptrStringer) String() string { return "stringer" }
+
+func TestDerefScalar(t *testing.T) {
+	i, f, s, b := -42, 3.14159, "text", true
+	u8 := uint8(255)
+	ps := ptrStringer(1)
+
+	tests := []struct {
+		fmtStr string
+		param  interface{}
+		want   string
+	}{
+		{"{}", &i, "-42"},
+		{"{:d}", &i, "-42"},
+		{"{:+05d}", &i, "-0042"},
+		{"{:#x}", &u8, "0xff"},
+		{"{:.2f}", &f, "3.14"},
+		{"{:.1%}", &f, "314.2%"},
+		{"{:>6}", &s, "  text"},
+		{"{}", &b, "true"},
+		{"{}", &ps, "stringer"},
+		{"{:t}", &i, "*int"},
+		{"{}", (*int)(nil), "<nil>"},
+	}
+
+	for _, test := range tests {
+		got, err := Fmt(test.fmtStr, test.param)
+		if err != nil {
+			t.Error(Must("Fmt({fmtStr}, {param}) Errored: {1}", test, err))
+		}
+		if got != test.want {
+			t.Error(Must("Fmt({fmtStr}, {param}) = {1}, Want: {want}", test, got))
+		}
+	}
+}